@@ -1,22 +1,33 @@
 package app
 
 import (
-	"fmt"
 	"gioui.org/app"
 	"gioui.org/font/gofont"
 	"gioui.org/io/system"
 	"gioui.org/layout"
 	"gioui.org/unit"
+	"gioui.org/widget"
+	"github.com/wrnrlr/foxtrot/browser"
+	"github.com/wrnrlr/foxtrot/editor"
+	"github.com/wrnrlr/foxtrot/kernel"
 	"github.com/wrnrlr/foxtrot/nbx"
 	"github.com/wrnrlr/foxtrot/notebook"
+	"github.com/wrnrlr/foxtrot/theme"
 	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
-func RunUI(p string) {
+// RunUI opens a window with a tab for every location, which is a path or an http(s) URL.
+// When isolated is set every tab gets a kernel of its own, otherwise the tabs share one kernel.
+func RunUI(isolated bool, locations ...string) {
 	gofont.Register()
 	go func() {
 		w := app.NewWindow(app.Title("Foxtrot"))
-		a := NewApp(p)
+		a := NewApp(isolated, locations...)
 		if err := a.loop(w); err != nil {
 			log.Fatal(err)
 		}
@@ -24,41 +35,317 @@ func RunUI(p string) {
 	app.Main()
 }
 
+// App is a workspace of notebooks in tabs.
 type App struct {
-	path string
-	nb   *notebook.Notebook
-	//br *browser.Browser
+	tabs   []*tab
+	active int
+	// isolated is set when new tabs get a kernel of their own.
+	isolated bool
+	// shared is the kernel of the tabs that share one.
+	shared *kernel.Kernel
+
+	br         *browser.Browser
+	styles     *theme.Styles
+	newTab     widget.Button
+	kernelMode widget.Button
+	saveCopy   widget.Button
+	saveFile   widget.Button
+	saveFileAs widget.Button
+	restore    widget.Button
+	discard    widget.Button
+	dismiss    widget.Button
+	reloadFile widget.Button
+	showDiff   widget.Button
+	keepFile   widget.Button
+	clear      widget.Button
+	evalAll    widget.Button
+	evalAbove  widget.Button
+	evalBelow  widget.Button
+	evalSel    widget.Button
+	continueEv widget.Button
+	dismissEv  widget.Button
+	err        error
+
+	// updated receives a value when the kernel of one of the tabs has made progress.
+	updated chan struct{}
+	// chosen receives the answers of save dialogs, choosing is set while a dialog is open.
+	chosen   chan chosenPath
+	choosing bool
+	// prompting is the tab whose path is asked in the banner when there is no native file dialog.
+	prompting    *tab
+	pathInput    *editor.Editor
+	promptSave   widget.Button
+	promptCancel widget.Button
 }
 
-func NewApp(p string) *App {
-	cells, err := nbx.ReadCell(p)
+func NewApp(isolated bool, locations ...string) *App {
+	styles := theme.DefaultStyles()
+	a := &App{isolated: isolated, styles: styles, chosen: make(chan chosenPath, 1), updated: make(chan struct{}, 1),
+		pathInput: &editor.Editor{SingleLine: true, Submit: true}}
+	dir, _ := os.Getwd()
+	a.br = browser.NewBrowser(styles, browser.DirURL(dir))
+	for _, l := range locations {
+		a.open(load(l))
+	}
+	a.recoverUntitled()
+	if len(a.tabs) == 0 {
+		a.open(browser.Loaded{})
+	}
+	return a
+}
+
+// load reads the notebook at a path or an http(s) URL, a path of a file that does not exist yet is a new notebook.
+func load(location string) browser.Loaded {
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		u, err := url.Parse(location)
+		if err != nil {
+			return browser.Loaded{Err: err}
+		}
+		doc, err := browser.Fetch(u)
+		return browser.Loaded{URL: u, Notebook: doc, Err: err}
+	}
+	abs, err := filepath.Abs(location)
 	if err != nil {
-		fmt.Printf("failed to open file")
+		abs = location
+	}
+	u := &url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}
+	doc, err := nbx.ReadFile(abs)
+	if err != nil && !os.IsNotExist(err) {
+		return browser.Loaded{URL: u, Err: err}
+	}
+	return browser.Loaded{URL: u, Notebook: doc}
+}
+
+// newKernel returns the kernel of a new tab.
+func (a *App) newKernel() *kernel.Kernel {
+	if a.isolated {
+		return kernel.NewKernel()
+	}
+	if a.shared == nil {
+		a.shared = kernel.NewKernel()
+	}
+	return a.shared
+}
+
+// addTab appends a tab with an empty notebook.
+func (a *App) addTab(p string, remote *url.URL) *tab {
+	t := &tab{path: p, remote: remote, nb: notebook.NewNotebookWithKernel(a.newKernel()), updated: a.updated}
+	t.forwardUpdates()
+	a.tabs = append(a.tabs, t)
+	return t
+}
+
+// open adds a tab with the loaded notebook and activates it, a file that is already open is activated.
+// A snapshot of unsaved changes to the file is offered to be restored.
+func (a *App) open(l browser.Loaded) {
+	if l.Err != nil {
+		a.err = l.Err
+		return
+	}
+	var p string
+	if l.URL != nil && !l.Remote() {
+		p = l.Path()
+		for i, t := range a.tabs {
+			if t.path == p {
+				a.activate(i)
+				return
+			}
+		}
+	}
+	var remote *url.URL
+	if l.URL != nil && l.Remote() {
+		remote = l.URL
+	}
+	t := a.addTab(p, remote)
+	k := t.nb.Kernel()
+	if doc := l.Notebook; doc != nil {
+		t.nb.AddCells(doc.Cells)
+		// Tabs that share a kernel continue its numbering.
+		if doc.PromptCount > k.PromptCount() || a.isolated {
+			t.nb.SetPromptCount(doc.PromptCount)
+		}
+	}
+	t.nb.MarkSaved()
+	if p != "" {
+		t.stamp = fileStamp(p)
+		if _, err := os.Stat(recoveryPath(p)); err == nil {
+			t.restore = recoveryPath(p)
+		}
+	}
+	a.err = nil
+	a.activate(len(a.tabs) - 1)
+}
+
+func (a *App) current() *tab {
+	return a.tabs[a.active]
+}
+
+// activate shows the tab at index i, relative locations in the browser are resolved against its directory.
+func (a *App) activate(i int) {
+	a.active = i
+	dir, _ := os.Getwd()
+	if p := a.current().path; p != "" {
+		dir = filepath.Dir(p)
+	}
+	a.br.SetBase(browser.DirURL(dir))
+}
+
+// closeTab saves the tab at index i and removes it, a notebook with unsaved changes is only closed
+// after a second click. The last tab is replaced by a new notebook.
+func (a *App) closeTab(i int) {
+	t := a.tabs[i]
+	if t.unsaved() && !t.confirmClose {
+		t.confirmClose = true
+		return
 	}
-	nb := notebook.NewNotebook()
-	nb.AddCells(cells)
-	//br := browser.NewBrowser()
-	return &App{p, nb}
+	if err := t.save(); err != nil {
+		a.err = err
+		return
+	}
+	t.removeSnapshot()
+	if a.prompting == t {
+		a.prompting = nil
+	}
+	t.nb.Close()
+	a.tabs = append(a.tabs[:i], a.tabs[i+1:]...)
+	if len(a.tabs) == 0 {
+		a.open(browser.Loaded{})
+		return
+	}
+	if a.active >= len(a.tabs) || a.active > i {
+		a.active--
+	}
+	a.activate(a.active)
 }
 
 func (a *App) loop(w *app.Window) error {
 	gtx := layout.NewContext(w.Queue())
+	autosave := time.NewTicker(autosaveInterval)
+	defer autosave.Stop()
+	watch := time.NewTicker(watchInterval)
+	defer watch.Stop()
 	for {
-		e := <-w.Events()
-		switch e := e.(type) {
-		case system.DestroyEvent:
-			a.save()
-			return e.Err
-		case system.FrameEvent:
-			gtx.Reset(e.Config, e.Size)
-			a.Layout(gtx)
-			e.Frame(gtx.Ops)
+		select {
+		case <-autosave.C:
+			a.autosave()
+		case <-watch.C:
+			if a.checkFiles() {
+				w.Invalidate()
+			}
+		case c := <-a.chosen:
+			a.choosing = false
+			a.saveChosen(c)
+			w.Invalidate()
+		case <-a.updated:
+			a.kernelEvents()
+			w.Invalidate()
+		case <-a.br.Ready():
+			a.br.Search()
+			w.Invalidate()
+		case l := <-a.br.Loaded():
+			if l.Err == nil {
+				a.open(l)
+			}
+			w.Invalidate()
+		case e := <-w.Events():
+			switch e := e.(type) {
+			case system.DestroyEvent:
+				a.saveAll()
+				return e.Err
+			case system.FrameEvent:
+				gtx.Reset(e.Config, e.Size)
+				a.Layout(gtx)
+				e.Frame(gtx.Ops)
+			}
 		}
 	}
 }
 
+// kernelEvents applies the results of the kernels to all tabs, also to those in the background
+// that are not laid out so that their titles show when they changed.
+func (a *App) kernelEvents() {
+	for _, t := range a.tabs {
+		t.nb.KernelEvents()
+	}
+}
+
 func (a *App) Event(gtx *layout.Context) interface{} {
-	a.nb.Event(gtx)
+	for i, t := range a.tabs {
+		for t.button.Clicked(gtx) {
+			a.activate(i)
+		}
+	}
+	for i := len(a.tabs) - 1; i >= 0; i-- {
+		if i < len(a.tabs) && a.tabs[i].close.Clicked(gtx) {
+			a.closeTab(i)
+		}
+	}
+	for a.newTab.Clicked(gtx) {
+		a.open(browser.Loaded{})
+	}
+	for a.kernelMode.Clicked(gtx) {
+		a.isolated = !a.isolated
+	}
+	nb := a.current().nb
+	if e, ok := nb.Event(gtx).(notebook.SaveNotebookEvent); ok {
+		if e.As {
+			a.saveAs()
+		} else {
+			a.saveCurrent()
+		}
+	}
+	if e, ok := a.br.Event(gtx).(browser.CopyEvent); ok {
+		nb.AddInput(e.Text)
+	}
+	for a.saveCopy.Clicked(gtx) {
+		a.saveLocalCopy()
+	}
+	for a.saveFile.Clicked(gtx) {
+		a.saveCurrent()
+	}
+	for a.saveFileAs.Clicked(gtx) {
+		a.saveAs()
+	}
+	for a.restore.Clicked(gtx) {
+		a.restoreSnapshot()
+	}
+	for a.discard.Clicked(gtx) {
+		a.discardSnapshot()
+	}
+	for a.dismiss.Clicked(gtx) {
+		a.err = nil
+	}
+	for a.reloadFile.Clicked(gtx) {
+		a.reload()
+	}
+	for a.showDiff.Clicked(gtx) {
+		a.toggleChanges()
+	}
+	for a.keepFile.Clicked(gtx) {
+		a.keepMine()
+	}
+	for a.clear.Clicked(gtx) {
+		nb.ClearOutputs()
+	}
+	for a.evalAll.Clicked(gtx) {
+		nb.EvalAll()
+	}
+	for a.evalAbove.Clicked(gtx) {
+		nb.EvalAbove()
+	}
+	for a.evalBelow.Clicked(gtx) {
+		nb.EvalBelow()
+	}
+	for a.evalSel.Clicked(gtx) {
+		nb.EvalSelected()
+	}
+	for a.continueEv.Clicked(gtx) {
+		nb.ContinueEval()
+	}
+	for a.dismissEv.Clicked(gtx) {
+		nb.DismissStopped()
+	}
+	a.promptEvents(gtx)
 	return nil
 }
 
@@ -68,23 +355,161 @@ func (a *App) Layout(gtx *layout.Context) {
 	i := layout.Inset{Top: margin}
 	i.Layout(gtx, func() {
 		f := layout.Flex{Axis: layout.Vertical}
-		//c1 := f.Rigid(gtx, func() {
-		//	a.br.Layout(gtx)
-		//})
+		c0 := layout.Rigid(func() {
+			a.layoutTabs(gtx)
+		})
+		toolbar := layout.Rigid(func() {
+			a.layoutToolbar(gtx)
+		})
+		c1 := layout.Rigid(func() {
+			a.layoutBanner(gtx)
+		})
 		c2 := layout.Flexed(1, func() {
-			a.nb.Layout(gtx)
+			h := layout.Flex{}
+			nb := layout.Flexed(1, func() {
+				if t := a.current(); t.diff != nil {
+					t.diff.Layout(gtx, a.styles)
+				} else {
+					t.nb.Layout(gtx)
+				}
+			})
+			br := layout.Rigid(func() {
+				a.br.Layout(gtx)
+			})
+			h.Layout(gtx, nb, br)
 		})
-		f.Layout(gtx, c2)
+		f.Layout(gtx, c0, toolbar, c1, c2)
 	})
 }
 
-func (a *App) save() {
-	fmt.Println("Quiting Foxtrot, Save notebook")
-	if a.path == "" {
+// saveAll writes the notebooks of the tabs that have a path,
+// the changes of the other notebooks are kept in a snapshot that is offered at the next start.
+func (a *App) saveAll() {
+	for _, t := range a.tabs {
+		if err := t.save(); err != nil {
+			log.Printf("failed to save notebook: %v", err)
+		}
+		if err := t.snapshot(); err != nil {
+			log.Printf("failed to write snapshot: %v", err)
+		}
+	}
+}
+
+// saveCurrent writes the notebook of the current tab to its path,
+// a notebook without a path asks where it should be saved.
+func (a *App) saveCurrent() {
+	t := a.current()
+	if t.path == "" {
+		a.saveAs()
+		return
+	}
+	a.saveTo(t, t.path)
+}
+
+// saveAs asks where the notebook of the current tab should be saved, with a native dialog when
+// there is one and otherwise in the banner.
+func (a *App) saveAs() {
+	if a.choosing {
+		return
+	}
+	t := a.current()
+	suggest := a.suggestPath(t)
+	a.choosing = true
+	go func() {
+		p, err := saveDialog(suggest)
+		a.chosen <- chosenPath{tab: t, path: p, err: err}
+	}()
+}
+
+// suggestPath is the file that is proposed when the notebook of t is saved under a new name.
+func (a *App) suggestPath(t *tab) string {
+	dir, _ := os.Getwd()
+	switch {
+	case t.path != "":
+		return t.path
+	case t.remote != nil:
+		return browser.LocalCopyPath(t.remote, dir)
+	}
+	return filepath.Join(dir, "Untitled.nbx")
+}
+
+// saveChosen saves the notebook to the path that was chosen in a dialog.
+func (a *App) saveChosen(c chosenPath) {
+	switch {
+	case c.err == errNoDialog:
+		a.prompting = c.tab
+		a.pathInput.SetText(a.suggestPath(c.tab))
+		a.pathInput.Focus()
+	case c.err != nil:
+		a.err = c.err
+	case c.path != "":
+		a.saveTo(c.tab, c.path)
+	}
+}
+
+// promptEvents handles the path that is typed in the banner when there is no native file dialog.
+func (a *App) promptEvents(gtx *layout.Context) {
+	submit := false
+	for _, e := range a.pathInput.Events(gtx) {
+		if _, ok := e.(editor.SubmitEvent); ok {
+			submit = true
+		}
+	}
+	for a.promptSave.Clicked(gtx) {
+		submit = true
+	}
+	for a.promptCancel.Clicked(gtx) {
+		a.prompting = nil
+	}
+	if !submit || a.prompting == nil {
+		return
+	}
+	p := strings.TrimSpace(a.pathInput.Text())
+	if p == "" {
+		return
+	}
+	if abs, err := filepath.Abs(p); err == nil {
+		p = abs
+	}
+	t := a.prompting
+	a.prompting = nil
+	a.saveTo(t, p)
+}
+
+// saveTo writes the notebook of t to p when its tab is still open, a path without an extension
+// is saved as a .nbx file.
+func (a *App) saveTo(t *tab, p string) {
+	if filepath.Ext(p) == "" {
+		p += ".nbx"
+	}
+	for _, o := range a.tabs {
+		if o != t {
+			continue
+		}
+		if err := t.writeTo(p); err == errChangedOnDisk {
+			// The banner offers to reload or keep the notebook.
+			return
+		} else if err != nil {
+			a.err = err
+			return
+		}
+		a.err = nil
+		if t == a.current() {
+			a.activate(a.active)
+		}
+	}
+}
+
+// saveLocalCopy writes a remote notebook to the working directory, from then on it is saved there.
+func (a *App) saveLocalCopy() {
+	t := a.current()
+	if t.remote == nil {
 		return
 	}
-	err := nbx.WriteFile(a.path, a.nb.Cells)
+	dir, err := os.Getwd()
 	if err != nil {
-		fmt.Println(err)
+		a.err = err
+		return
 	}
+	a.saveTo(t, browser.LocalCopyPath(t.remote, dir))
 }
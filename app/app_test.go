@@ -0,0 +1,32 @@
+package app
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wrnrlr/foxtrot/browser"
+	"testing"
+	"time"
+)
+
+func TestBackgroundTabUpdated(t *testing.T) {
+	a := &App{br: &browser.Browser{}, updated: make(chan struct{}, 1)}
+	a.open(browser.Loaded{})
+	bg := a.current()
+	defer bg.nb.Close()
+	bg.nb.AddInput("1+1")
+	bg.nb.MarkSaved()
+	a.open(browser.Loaded{})
+	defer a.current().nb.Close()
+	assert.Equal(t, 1, a.active)
+
+	bg.nb.EvalAll()
+	timeout := time.After(10 * time.Second)
+	for bg.nb.Size() < 2 {
+		select {
+		case <-a.updated:
+			a.kernelEvents()
+		case <-timeout:
+			t.Fatal("the result of the tab in the background was not applied")
+		}
+	}
+	assert.Equal(t, "Untitled *", bg.title())
+}
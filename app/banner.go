@@ -0,0 +1,84 @@
+package app
+
+import (
+	"fmt"
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"github.com/wrnrlr/foxtrot/util"
+	"path/filepath"
+)
+
+// bannerColor is the background of the banner above the notebook.
+var bannerColor = util.Rgb(0xfff8e1)
+
+// layoutBanner shows the error of the last action, the path of a notebook that is saved when there
+// is no native file dialog, the cells that were not evaluated after an error, that the file of the notebook changed on disk, an offer to restore
+// the unsaved changes of an earlier session and that a remote notebook is read-only.
+func (a *App) layoutBanner(gtx *layout.Context) {
+	th := a.styles.Theme
+	t := a.current()
+	var msg string
+	var buttons []layout.FlexChild
+	button := func(label string, b *widget.Button) layout.FlexChild {
+		return layout.Rigid(func() {
+			layout.Inset{Left: unit.Sp(8)}.Layout(gtx, func() {
+				th.Button(label).Layout(gtx, b)
+			})
+		})
+	}
+	switch {
+	case a.err != nil:
+		msg = a.err.Error()
+		buttons = append(buttons, button("×", &a.dismiss))
+	case a.prompting == t:
+		buttons = append(buttons, button("Save", &a.promptSave), button("Cancel", &a.promptCancel))
+	case t.nb.Stopped() > 0:
+		msg = fmt.Sprintf("The evaluation stopped after an error, %d cells were not evaluated", t.nb.Stopped())
+		buttons = append(buttons, button("Continue", &a.continueEv), button("×", &a.dismissEv))
+	case t.changedOnDisk:
+		msg = filepath.Base(t.path) + " changed on disk"
+		label := "Show changes"
+		if t.diff != nil {
+			label = "Hide changes"
+		}
+		buttons = append(buttons, button("Reload", &a.reloadFile), button(label, &a.showDiff),
+			button("Keep mine", &a.keepFile))
+	case t.restore != "":
+		msg = "There are unsaved changes from an earlier session"
+		buttons = append(buttons, button("Restore", &a.restore), button("Discard", &a.discard))
+	case t.remote != nil:
+		msg = "Read-only: " + t.remote.String() + ", changes are not saved"
+		buttons = append(buttons, button("Save a local copy", &a.saveCopy))
+	default:
+		return
+	}
+	var macro op.MacroOp
+	macro.Record(gtx.Ops)
+	layout.UniformInset(unit.Sp(8)).Layout(gtx, func() {
+		f := layout.Flex{Alignment: layout.Middle}
+		body := layout.Flexed(1, func() {
+			if a.err == nil && a.prompting == t {
+				st := a.styles.Text
+				st.Hint = "Path of the notebook"
+				st.HintColor = util.LightGrey
+				st.Layout(gtx, a.pathInput)
+				return
+			}
+			l := th.Label(unit.Sp(14), msg)
+			l.Color = util.Black
+			if a.err != nil {
+				l.Color = util.Red
+			}
+			l.Layout(gtx)
+		})
+		f.Layout(gtx, append([]layout.FlexChild{body}, buttons...)...)
+	})
+	macro.Stop()
+	paint.ColorOp{Color: bannerColor}.Add(gtx.Ops)
+	paint.PaintOp{Rect: f32.Rectangle{Max: util.ToPointF(gtx.Dimensions.Size)}}.Add(gtx.Ops)
+	macro.Add()
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// errNoDialog is returned by saveDialog when there is no program that shows a native file dialog.
+var errNoDialog = errors.New("no file dialog")
+
+// chosenPath is the answer of the dialog that asked where the notebook of a tab should be saved.
+type chosenPath struct {
+	tab  *tab
+	path string
+	err  error
+}
+
+// saveDialog asks for the file to save a notebook to with a native dialog that proposes suggest,
+// the path is empty when the dialog was cancelled.
+func saveDialog(suggest string) (string, error) {
+	cmd := saveDialogCommand(suggest)
+	if cmd == nil {
+		return "", errNoDialog
+	}
+	out, err := cmd.Output()
+	if _, ok := err.(*exec.ExitError); ok {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func saveDialogCommand(suggest string) *exec.Cmd {
+	dir, name := filepath.Split(suggest)
+	if runtime.GOOS == "darwin" {
+		script := fmt.Sprintf(`POSIX path of (choose file name with prompt "Save notebook" default name %q default location POSIX file %q)`, name, dir)
+		return exec.Command("osascript", "-e", script)
+	}
+	if p, err := exec.LookPath("zenity"); err == nil {
+		return exec.Command(p, "--file-selection", "--save", "--confirm-overwrite", "--filename="+suggest)
+	}
+	if p, err := exec.LookPath("kdialog"); err == nil {
+		return exec.Command(p, "--getsavefilename", suggest)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"gioui.org/layout"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/editor"
+	"github.com/wrnrlr/foxtrot/nbx"
+	"github.com/wrnrlr/foxtrot/theme"
+	"github.com/wrnrlr/foxtrot/util"
+)
+
+// diffView shows the cells of a notebook that differ from its file on disk,
+// removed cells are only in the notebook and added cells only on disk.
+type diffView struct {
+	lines []nbx.DiffLine
+	list  layout.List
+}
+
+func newDiffView(mine, disk cell.Cells) *diffView {
+	return &diffView{lines: nbx.DiffLines(mine, disk, nbx.Diff(mine, disk)), list: layout.List{Axis: layout.Vertical}}
+}
+
+func (d *diffView) Layout(gtx *layout.Context, styles *theme.Styles) {
+	st := styles.Code
+	inset := layout.Inset{Left: unit.Sp(16), Right: unit.Sp(16), Top: unit.Sp(4), Bottom: unit.Sp(4)}
+	d.list.Layout(gtx, len(d.lines), func(i int) {
+		l := d.lines[i]
+		inset.Layout(gtx, func() {
+			sign := "  "
+			switch l.Op {
+			case nbx.Removed:
+				sign = "- "
+				paint.ColorOp{Color: util.Red}.Add(gtx.Ops)
+			case nbx.Added:
+				sign = "+ "
+				paint.ColorOp{Color: util.LightGreen}.Add(gtx.Ops)
+			case nbx.Moved:
+				sign = "> "
+				paint.ColorOp{Color: util.Blue}.Add(gtx.Ops)
+			default:
+				paint.ColorOp{Color: util.Grey}.Add(gtx.Ops)
+			}
+			editor.Label{}.Layout(gtx, st.Shaper, st.Font, sign+l.Text)
+		})
+	})
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"fmt"
+	"github.com/wrnrlr/foxtrot/nbx"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// autosaveInterval is the time between the snapshots of notebooks with unsaved changes.
+const autosaveInterval = 30 * time.Second
+
+// recoveryDir is the directory with the snapshots of notebooks that were never saved.
+func recoveryDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "foxtrot", "recovery"), nil
+}
+
+// recoveryPath is the snapshot of the notebook at path, a hidden file next to it.
+func recoveryPath(path string) string {
+	dir, name := filepath.Split(path)
+	return filepath.Join(dir, "."+name+".recovery.nbx")
+}
+
+// snapshot writes the unsaved changes of the notebook to its recovery file, a remote notebook
+// and one with a snapshot that is still offered to be restored are skipped.
+func (t *tab) snapshot() error {
+	if t.remote != nil || t.restore != "" {
+		return nil
+	}
+	if !t.nb.Dirty() {
+		t.removeSnapshot()
+		return nil
+	}
+	p := t.recovery
+	if p == "" && t.path != "" {
+		p = recoveryPath(t.path)
+	} else if p == "" {
+		dir, err := recoveryDir()
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+		p = filepath.Join(dir, fmt.Sprintf("untitled-%d.nbx", time.Now().UnixNano()))
+	}
+	if err := nbx.WriteNotebookFile(p, t.document()); err != nil {
+		return err
+	}
+	t.recovery = p
+	return nil
+}
+
+// removeSnapshot removes the recovery file of the notebook, its changes were saved or discarded.
+func (t *tab) removeSnapshot() {
+	if t.recovery != "" {
+		os.Remove(t.recovery)
+		t.recovery = ""
+	}
+}
+
+// autosave writes a snapshot of every notebook with unsaved changes.
+func (a *App) autosave() {
+	for _, t := range a.tabs {
+		if err := t.snapshot(); err != nil {
+			log.Printf("failed to write snapshot: %v", err)
+		}
+	}
+}
+
+// recoverUntitled opens a tab for every snapshot of a notebook that was never saved,
+// the tab offers to restore it.
+func (a *App) recoverUntitled() {
+	dir, err := recoveryDir()
+	if err != nil {
+		return
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), "untitled-") && filepath.Ext(f.Name()) == ".nbx" {
+			t := a.addTab("", nil)
+			t.restore = filepath.Join(dir, f.Name())
+		}
+	}
+}
+
+// restoreSnapshot replaces the notebook of the current tab by its snapshot,
+// the restored changes are unsaved until the notebook is saved.
+func (a *App) restoreSnapshot() {
+	t := a.current()
+	doc, err := nbx.ReadFile(t.restore)
+	if err != nil {
+		a.err = err
+		return
+	}
+	t.replace(doc)
+	t.recovery, t.restore = t.restore, ""
+}
+
+// discardSnapshot removes the snapshot that was offered to be restored,
+// the tab of an untitled notebook that was opened for it is closed.
+func (a *App) discardSnapshot() {
+	t := a.current()
+	os.Remove(t.restore)
+	t.restore = ""
+	if t.path == "" && t.nb.Size() == 0 {
+		a.closeTab(a.active)
+	}
+}
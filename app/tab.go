@@ -0,0 +1,162 @@
+package app
+
+import (
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"github.com/wrnrlr/foxtrot/nbx"
+	"github.com/wrnrlr/foxtrot/notebook"
+	"github.com/wrnrlr/foxtrot/util"
+	"image"
+	"net/url"
+	"path"
+	"path/filepath"
+)
+
+// tab is a notebook that is open in the workspace.
+type tab struct {
+	// path is the file the notebook is saved to, it is empty for a new or remote notebook.
+	path string
+	// remote is the URL of a notebook that was downloaded, it is read-only until a local copy is saved.
+	remote *url.URL
+	nb     *notebook.Notebook
+	// confirmClose is set after the first click on close of a notebook with changes that can not be saved.
+	confirmClose bool
+	// recovery is the snapshot of the unsaved changes that was written last, restore is a snapshot of
+	// an earlier session that is offered to be restored.
+	recovery string
+	restore  string
+	// stamp is the version of the file that was read or written last, changedOnDisk is set when
+	// the file was changed by someone else since then and diff shows how.
+	stamp         stamp
+	changedOnDisk bool
+	diff          *diffView
+	// updated is the channel of the app that receives a value when the kernel has made progress.
+	updated chan struct{}
+
+	button widget.Button
+	close  widget.Button
+}
+
+func (t *tab) title() string {
+	name := "Untitled"
+	switch {
+	case t.remote != nil:
+		name = path.Base(t.remote.Path)
+	case t.path != "":
+		name = filepath.Base(t.path)
+	}
+	if t.nb.Dirty() {
+		name += " *"
+	}
+	return name
+}
+
+// save writes the notebook to its path when it changed.
+func (t *tab) save() error {
+	if t.path == "" || !t.nb.Dirty() {
+		return nil
+	}
+	return t.writeTo(t.path)
+}
+
+// writeTo writes the notebook to p, from then on it is saved there and its snapshot is removed.
+// The file of the notebook is not overwritten when it changed on disk since it was read.
+func (t *tab) writeTo(p string) error {
+	if p == t.path && t.modifiedOnDisk() {
+		t.changedOnDisk = true
+		return errChangedOnDisk
+	}
+	if err := nbx.WriteNotebookFile(p, t.document()); err != nil {
+		return err
+	}
+	t.nb.MarkSaved()
+	t.removeSnapshot()
+	t.path, t.remote = p, nil
+	t.stamp = fileStamp(p)
+	t.changedOnDisk, t.diff = false, nil
+	return nil
+}
+
+// replace shows doc in the tab instead of its notebook, the kernel of the notebook is kept.
+func (t *tab) replace(doc *nbx.Notebook) {
+	nb := notebook.NewNotebookWithKernel(t.nb.Kernel())
+	nb.AddCells(doc.Cells)
+	if doc.PromptCount > nb.PromptCount() {
+		nb.SetPromptCount(doc.PromptCount)
+	}
+	t.nb.Close()
+	t.nb = nb
+	t.forwardUpdates()
+}
+
+// forwardUpdates sends a value on updated whenever the notebook is updated, until it is closed.
+func (t *tab) forwardUpdates() {
+	nb, updated := t.nb, t.updated
+	go func() {
+		for range nb.Updated() {
+			select {
+			case updated <- struct{}{}:
+			default:
+			}
+		}
+	}()
+}
+
+func (t *tab) document() *nbx.Notebook {
+	return &nbx.Notebook{Cells: t.nb.Cells, PromptCount: t.nb.PromptCount()}
+}
+
+// unsaved reports whether closing the tab would lose changes.
+func (t *tab) unsaved() bool {
+	return t.path == "" && t.nb.Dirty()
+}
+
+// layoutTabs shows a button for every tab with a button to close it, followed by
+// a button for a new tab, the buttons that save the current tab and one that chooses the kernel of new tabs.
+func (a *App) layoutTabs(gtx *layout.Context) {
+	th := a.styles.Theme
+	var children []layout.FlexChild
+	for i, t := range a.tabs {
+		i, t := i, t
+		children = append(children, layout.Rigid(func() {
+			layout.Inset{Right: unit.Sp(4)}.Layout(gtx, func() {
+				b := th.Button(t.title())
+				if i != a.active {
+					b.Background = util.LightGrey
+				}
+				b.Layout(gtx, &t.button)
+			})
+		}), layout.Rigid(func() {
+			layout.Inset{Right: unit.Sp(8)}.Layout(gtx, func() {
+				label := "×"
+				if t.confirmClose {
+					label = "Discard changes"
+				}
+				th.Button(label).Layout(gtx, &t.close)
+			})
+		}))
+	}
+	children = append(children, layout.Rigid(func() {
+		th.Button("+").Layout(gtx, &a.newTab)
+	}), layout.Flexed(1, func() {
+		gtx.Dimensions = layout.Dimensions{Size: image.Point{X: gtx.Constraints.Width.Max}}
+	}), layout.Rigid(func() {
+		layout.Inset{Right: unit.Sp(4)}.Layout(gtx, func() {
+			th.Button("Save").Layout(gtx, &a.saveFile)
+		})
+	}), layout.Rigid(func() {
+		layout.Inset{Right: unit.Sp(8)}.Layout(gtx, func() {
+			th.Button("Save As").Layout(gtx, &a.saveFileAs)
+		})
+	}), layout.Rigid(func() {
+		label := "Shared kernel"
+		if a.isolated {
+			label = "Isolated kernel"
+		}
+		th.Button(label).Layout(gtx, &a.kernelMode)
+	}))
+	layout.UniformInset(unit.Sp(4)).Layout(gtx, func() {
+		layout.Flex{Alignment: layout.Middle}.Layout(gtx, children...)
+	})
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"fmt"
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"github.com/wrnrlr/foxtrot/util"
+	"image"
+)
+
+// layoutToolbar shows the commands that evaluate the cells of the current notebook or clear their outputs,
+// followed by the progress of an evaluation of several cells.
+func (a *App) layoutToolbar(gtx *layout.Context) {
+	th := a.styles.Theme
+	nb := a.current().nb
+	button := func(label string, b *widget.Button) layout.FlexChild {
+		return layout.Rigid(func() {
+			layout.Inset{Right: unit.Sp(4)}.Layout(gtx, func() {
+				th.Button(label).Layout(gtx, b)
+			})
+		})
+	}
+	progress := layout.Rigid(func() {
+		remaining, total := nb.Progress()
+		if total == 0 {
+			return
+		}
+		layout.Inset{Left: unit.Sp(8)}.Layout(gtx, func() {
+			f := layout.Flex{Alignment: layout.Middle}
+			bar := layout.Rigid(func() {
+				layoutProgress(gtx, total-remaining, total)
+			})
+			label := layout.Rigid(func() {
+				layout.Inset{Left: unit.Sp(8)}.Layout(gtx, func() {
+					l := th.Label(unit.Sp(14), fmt.Sprintf("%d of %d cells remaining", remaining, total))
+					l.Color = util.Grey
+					l.Layout(gtx)
+				})
+			})
+			f.Layout(gtx, bar, label)
+		})
+	})
+	layout.Inset{Left: unit.Sp(4), Right: unit.Sp(4), Bottom: unit.Sp(4)}.Layout(gtx, func() {
+		layout.Flex{Alignment: layout.Middle}.Layout(gtx,
+			button("Evaluate all", &a.evalAll),
+			button("Evaluate above", &a.evalAbove),
+			button("Evaluate below", &a.evalBelow),
+			button("Evaluate selection", &a.evalSel),
+			button("Clear all outputs", &a.clear),
+			progress)
+	})
+}
+
+// layoutProgress draws a bar that is filled for the part done of total.
+func layoutProgress(gtx *layout.Context, done, total int) {
+	w, h := gtx.Px(unit.Dp(120)), gtx.Px(unit.Dp(6))
+	paint.ColorOp{Color: util.LightGrey}.Add(gtx.Ops)
+	paint.PaintOp{Rect: f32.Rectangle{Max: f32.Point{X: float32(w), Y: float32(h)}}}.Add(gtx.Ops)
+	paint.ColorOp{Color: util.LightBlue}.Add(gtx.Ops)
+	paint.PaintOp{Rect: f32.Rectangle{Max: f32.Point{X: float32(w * done / total), Y: float32(h)}}}.Add(gtx.Ops)
+	gtx.Dimensions = layout.Dimensions{Size: image.Point{X: w, Y: h}}
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"errors"
+	"github.com/wrnrlr/foxtrot/nbx"
+	"os"
+	"time"
+)
+
+// watchInterval is the time between the checks whether the files of the open notebooks changed on disk.
+const watchInterval = 2 * time.Second
+
+// errChangedOnDisk is returned when a notebook is saved to a file that changed since it was read,
+// the notebook is not written because the changes on disk would be lost.
+var errChangedOnDisk = errors.New("the file changed on disk, reload it or keep your version before saving")
+
+// stamp identifies the version of a file on disk by its modification time and size,
+// the zero stamp is a file that does not exist.
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func fileStamp(p string) stamp {
+	info, err := os.Stat(p)
+	if err != nil {
+		return stamp{}
+	}
+	return stamp{info.ModTime(), info.Size()}
+}
+
+// modifiedOnDisk reports whether the file of the notebook was written by someone else
+// since it was read or saved, a file that was removed is not modified.
+func (t *tab) modifiedOnDisk() bool {
+	if t.path == "" {
+		return false
+	}
+	s := fileStamp(t.path)
+	return s != stamp{} && s != t.stamp
+}
+
+// checkFiles marks the tabs whose file changed on disk, it reports whether a tab was marked.
+func (a *App) checkFiles() bool {
+	changed := false
+	for _, t := range a.tabs {
+		if !t.changedOnDisk && t.modifiedOnDisk() {
+			t.changedOnDisk = true
+			changed = true
+		}
+	}
+	return changed
+}
+
+// reload replaces the notebook of the current tab by its file on disk, the changes in the tab are lost.
+func (a *App) reload() {
+	t := a.current()
+	doc, err := nbx.ReadFile(t.path)
+	if err != nil {
+		a.err = err
+		return
+	}
+	t.replace(doc)
+	t.nb.MarkSaved()
+	t.removeSnapshot()
+	t.stamp = fileStamp(t.path)
+	t.changedOnDisk, t.diff = false, nil
+}
+
+// keepMine ignores the changes on disk to the file of the current tab, the next save overwrites them.
+func (a *App) keepMine() {
+	t := a.current()
+	t.stamp = fileStamp(t.path)
+	t.changedOnDisk, t.diff = false, nil
+}
+
+// toggleChanges shows or hides how the file of the current tab on disk differs from its notebook.
+func (a *App) toggleChanges() {
+	t := a.current()
+	if t.diff != nil {
+		t.diff = nil
+		return
+	}
+	doc, err := nbx.ReadFile(t.path)
+	if err != nil {
+		a.err = err
+		return
+	}
+	t.diff = newDiffView(t.nb.Cells, doc.Cells)
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wrnrlr/foxtrot/browser"
+	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/nbx"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveChangedOnDisk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "foxtrot")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	p := filepath.Join(dir, "a.nbx")
+	c := cell.NewCell(cell.Input, "", nil)
+	c.SetText("1+1")
+	assert.Nil(t, nbx.WriteNotebookFile(p, &nbx.Notebook{Cells: cell.Cells{c}}))
+
+	a := &App{br: &browser.Browser{}}
+	a.open(load(p))
+	tb := a.current()
+	defer tb.nb.Close()
+	tb.nb.InsertCell(1, cell.Paragraph)
+	assert.True(t, tb.nb.Dirty())
+
+	disk := []byte("changed by another program, a longer file than before")
+	assert.Nil(t, ioutil.WriteFile(p, disk, 0644))
+	assert.Equal(t, errChangedOnDisk, tb.save())
+	b, _ := ioutil.ReadFile(p)
+	assert.Equal(t, disk, b)
+	assert.True(t, tb.changedOnDisk)
+
+	a.saveCurrent()
+	assert.Nil(t, a.err)
+	b, _ = ioutil.ReadFile(p)
+	assert.Equal(t, disk, b)
+
+	a.keepMine()
+	assert.Nil(t, tb.save())
+	doc, err := nbx.ReadFile(p)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(doc.Cells))
+	assert.False(t, tb.changedOnDisk)
+}
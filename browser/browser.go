@@ -1,62 +1,319 @@
 package browser
 
 import (
-	"gioui.org/font"
 	"gioui.org/layout"
-	"gioui.org/text"
+	"gioui.org/op/paint"
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"github.com/wrnrlr/foxtrot/editor"
+	"github.com/wrnrlr/foxtrot/search"
+	"github.com/wrnrlr/foxtrot/theme"
 	"github.com/wrnrlr/foxtrot/util"
+	"log"
 	"net/url"
+	"sync"
 )
 
+// maxResults is the number of search results that are listed.
+const maxResults = 30
+
+// CopyEvent is generated when an example is clicked, its text is copied into the notebook as a new input cell.
+type CopyEvent struct {
+	Text string
+}
+
+// Browser is a side pane with the documentation of symbols. Typing in its input searches the documentation,
+// a result opens the page of its symbol. A location of a notebook in the input is opened as a new notebook.
 type Browser struct {
-	input *editor.Editor
-	add   widget.Button
-	url2  *url.URL
-	err   error
+	visible bool
+	toggle  widget.Button
+	back    widget.Button
+	forward widget.Button
+	input   *editor.Editor
 
-	st editor.EditorStyle
+	history history
+	page    *page
+	// searching is set while the results are shown instead of the page.
+	searching bool
+	results   []result
+	list      layout.List
+	styles    *theme.Styles
+	// base is the URL that relative locations are resolved against.
+	base *url.URL
+	// ready receives a value when the index was opened.
+	ready  chan struct{}
+	loaded chan Loaded
+
+	mu  sync.Mutex
+	err error
 }
 
-func NewBrowser() *Browser {
-	input := &editor.Editor{SingleLine: true}
-	shaper := font.Default()
-	st := editor.EditorStyle{
-		Font:       text.Font{Size: unit.Sp(18)},
-		Color:      util.LightGrey,
-		CaretColor: util.Black,
-		Hint:       "Temporary file",
-		HintColor:  util.LightGrey,
-		Shaper:     shaper}
-	b := Browser{input: input, st: st}
-	return &b
+type result struct {
+	search.Result
+	button widget.Button
 }
 
-func (b *Browser) url() *url.URL {
-	u, _ := url.Parse(b.input.Text())
-	return u
+// NewBrowser returns a browser that resolves relative locations against base.
+func NewBrowser(styles *theme.Styles, base *url.URL) *Browser {
+	b := &Browser{
+		input:  &editor.Editor{SingleLine: true, Submit: true},
+		list:   layout.List{Axis: layout.Vertical},
+		styles: styles,
+		base:   base,
+		ready:  make(chan struct{}, 1),
+		loaded: make(chan Loaded, 1)}
+	go func() {
+		openIndex()
+		b.ready <- struct{}{}
+	}()
+	return b
 }
 
-func (b *Browser) domain(gtx layout.Context) string {
-	return b.url().Host
+// SetBase sets the URL that relative locations are resolved against.
+func (b *Browser) SetBase(base *url.URL) {
+	b.base = base
 }
 
-func (b *Browser) filename(gtx layout.Context) string {
-	return b.url().Host
+// Ready receives a value when the documentation can be searched.
+func (b *Browser) Ready() <-chan struct{} {
+	return b.ready
 }
 
-func (b *Browser) Layout(gtx *layout.Context) {
-	padding := layout.UniformInset(unit.Sp(20))
-	padding.Layout(gtx, func() {
-		b.st.Layout(gtx, b.input)
+// Loaded receives the notebooks that were opened from the input.
+func (b *Browser) Loaded() <-chan Loaded {
+	return b.loaded
+}
+
+// index is shared by the browsers of all windows.
+var index struct {
+	once sync.Once
+	mu   sync.Mutex
+	ix   *search.Index
+}
+
+// openIndex opens the index in the cache directory and re-indexes the builtins when their definitions changed,
+// the index is kept in memory when it can not be opened.
+func openIndex() {
+	index.once.Do(func() {
+		path, err := search.DefaultPath()
+		var ix *search.Index
+		if err == nil {
+			ix, err = search.Open(path)
+		}
+		if err != nil {
+			log.Printf("failed to open documentation index: %v", err)
+			if ix, err = search.NewMemIndex(); err != nil {
+				log.Printf("failed to create documentation index: %v", err)
+				return
+			}
+		}
+		if _, err := ix.Sync(search.Builtins()); err != nil {
+			log.Printf("failed to index documentation: %v", err)
+		}
+		index.mu.Lock()
+		index.ix = ix
+		index.mu.Unlock()
 	})
 }
 
-func (b *Browser) Event(gtx *layout.Context) {
+// sharedIndex returns the index, it is nil until it was opened.
+func sharedIndex() *search.Index {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	return index.ix
+}
+
+// Search lists the symbols whose documentation matches the text of the input.
+func (b *Browser) Search() {
+	q := b.input.Text()
+	b.searching = q != "" && !IsLocation(q)
+	b.setErr(nil)
+	b.results = nil
+	ix := sharedIndex()
+	if ix == nil || !b.searching {
+		return
+	}
+	results, err := ix.Search(q, maxResults)
+	b.setErr(err)
+	for _, r := range results {
+		b.results = append(b.results, result{Result: r})
+	}
+}
+
+func (b *Browser) setErr(err error) {
+	b.mu.Lock()
+	b.err = err
+	b.mu.Unlock()
+}
+
+func (b *Browser) getErr() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
+
+// load opens the notebook at location in the background, the result is received from Loaded.
+func (b *Browser) load(location string) {
+	u, err := Resolve(location, b.base)
+	if err != nil {
+		b.setErr(err)
+		return
+	}
+	go func() {
+		nb, err := Fetch(u)
+		b.setErr(err)
+		b.loaded <- Loaded{URL: u, Notebook: nb, Err: err}
+	}()
+}
+
+// Open shows the page of the symbol with the name.
+func (b *Browser) Open(name string) {
+	b.history.visit(name)
+	b.show(name)
+}
 
+func (b *Browser) show(name string) {
+	b.visible = true
+	b.searching = false
+	b.page = newPage(name, b.styles)
 }
 
-type page struct {
+// Back shows the previous page.
+func (b *Browser) Back() {
+	if name, ok := b.history.goBack(); ok {
+		b.show(name)
+	}
+}
+
+// Forward shows the page that was left with Back.
+func (b *Browser) Forward() {
+	if name, ok := b.history.goForward(); ok {
+		b.show(name)
+	}
+}
+
+// submit opens the notebook at the location in the input, the symbol with the name in the input
+// or the best result when there is no such builtin.
+func (b *Browser) submit() {
+	name := b.input.Text()
+	if IsLocation(name) {
+		b.load(name)
+	} else if _, ok := search.Lookup(name); ok {
+		b.Open(name)
+	} else if len(b.results) > 0 {
+		b.Open(b.results[0].Name)
+	}
+}
+
+func (b *Browser) Event(gtx *layout.Context) interface{} {
+	for b.toggle.Clicked(gtx) {
+		b.visible = !b.visible
+		if b.visible {
+			b.input.Focus()
+		}
+	}
+	for b.back.Clicked(gtx) {
+		b.Back()
+	}
+	for b.forward.Clicked(gtx) {
+		b.Forward()
+	}
+	for _, e := range b.input.Events(gtx) {
+		switch e.(type) {
+		case editor.ChangeEvent:
+			b.Search()
+		case editor.SubmitEvent:
+			b.submit()
+		}
+	}
+	for i := range b.results {
+		if b.searching && b.results[i].button.Clicked(gtx) {
+			b.Open(b.results[i].Name)
+			break
+		}
+	}
+	if b.page != nil && !b.searching {
+		return b.page.Event(gtx)
+	}
+	return nil
+}
+
+func (b *Browser) Layout(gtx *layout.Context) {
+	th := b.styles.Theme
+	if !b.visible {
+		layout.UniformInset(unit.Sp(8)).Layout(gtx, func() {
+			th.Button("Docs").Layout(gtx, &b.toggle)
+		})
+		return
+	}
+	w := gtx.Px(unit.Sp(420))
+	gtx.Constraints.Width = layout.Constraint{Min: w, Max: w}
+	inset := layout.UniformInset(unit.Sp(8))
+	f := layout.Flex{Axis: layout.Vertical}
+	header := layout.Rigid(func() {
+		inset.Layout(gtx, func() {
+			h := layout.Flex{Alignment: layout.Middle}
+			back := layout.Rigid(func() {
+				th.Button("<").Layout(gtx, &b.back)
+			})
+			forward := layout.Rigid(func() {
+				th.Button(">").Layout(gtx, &b.forward)
+			})
+			input := layout.Flexed(1, func() {
+				layout.Inset{Left: unit.Sp(8), Right: unit.Sp(8)}.Layout(gtx, func() {
+					st := b.styles.Text
+					st.Hint = "Search documentation or open a notebook"
+					st.HintColor = util.LightGrey
+					st.Layout(gtx, b.input)
+				})
+			})
+			hide := layout.Rigid(func() {
+				th.Button("×").Layout(gtx, &b.toggle)
+			})
+			h.Layout(gtx, back, forward, input, hide)
+		})
+	})
+	errLabel := layout.Rigid(func() {
+		if err := b.getErr(); err != nil {
+			inset.Layout(gtx, func() {
+				paint.ColorOp{Color: util.Red}.Add(gtx.Ops)
+				editor.Label{}.Layout(gtx, b.styles.Text.Shaper, b.styles.Text.Font, err.Error())
+			})
+		}
+	})
+	body := layout.Flexed(1, func() {
+		if b.searching {
+			b.layoutResults(gtx)
+		} else if b.page != nil {
+			b.page.Layout(gtx)
+		}
+	})
+	f.Layout(gtx, header, errLabel, body)
+}
+
+func (b *Browser) layoutResults(gtx *layout.Context) {
+	inset := layout.UniformInset(unit.Sp(8))
+	b.list.Layout(gtx, len(b.results), func(i int) {
+		r := &b.results[i]
+		clickable(gtx, &r.button, func() {
+			inset.Layout(gtx, func() {
+				b.layoutResult(gtx, r.Result)
+			})
+		})
+	})
+}
+
+// layoutResult shows the name of a symbol above its usage.
+func (b *Browser) layoutResult(gtx *layout.Context, r search.Result) {
+	st := b.styles.Text
+	f := layout.Flex{Axis: layout.Vertical}
+	name := layout.Rigid(func() {
+		paint.ColorOp{Color: st.Color}.Add(gtx.Ops)
+		editor.Label{MaxLines: 1}.Layout(gtx, st.Shaper, b.styles.Code.Font, r.Name)
+	})
+	usage := layout.Rigid(func() {
+		paint.ColorOp{Color: util.Grey}.Add(gtx.Ops)
+		editor.Label{}.Layout(gtx, st.Shaper, st.Font, plain(r.Usage))
+	})
+	f.Layout(gtx, name, usage)
 }
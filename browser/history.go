@@ -0,0 +1,44 @@
+package browser
+
+// history is the list of visited pages that can be navigated backward and forward.
+type history struct {
+	back    []string
+	forward []string
+	current string
+}
+
+// visit makes name the current page, the pages that were ahead of the previous one are forgotten.
+func (h *history) visit(name string) {
+	if name == h.current {
+		return
+	}
+	if h.current != "" {
+		h.back = append(h.back, h.current)
+	}
+	h.current = name
+	h.forward = nil
+}
+
+// goBack returns the previous page, it reports false when there is none.
+func (h *history) goBack() (string, bool) {
+	n := len(h.back)
+	if n == 0 {
+		return "", false
+	}
+	h.forward = append(h.forward, h.current)
+	h.current = h.back[n-1]
+	h.back = h.back[:n-1]
+	return h.current, true
+}
+
+// goForward returns the page that was left with goBack, it reports false when there is none.
+func (h *history) goForward() (string, bool) {
+	n := len(h.forward)
+	if n == 0 {
+		return "", false
+	}
+	h.back = append(h.back, h.current)
+	h.current = h.forward[n-1]
+	h.forward = h.forward[:n-1]
+	return h.current, true
+}
@@ -0,0 +1,33 @@
+package browser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	var h history
+	_, ok := h.goBack()
+	assert.False(t, ok)
+	h.visit("Sin")
+	h.visit("Cos")
+	h.visit("Cos")
+	h.visit("Tan")
+	name, ok := h.goBack()
+	assert.True(t, ok)
+	assert.Equal(t, "Cos", name)
+	name, _ = h.goBack()
+	assert.Equal(t, "Sin", name)
+	_, ok = h.goBack()
+	assert.False(t, ok)
+	name, ok = h.goForward()
+	assert.True(t, ok)
+	assert.Equal(t, "Cos", name)
+
+	h.visit("Plot")
+	_, ok = h.goForward()
+	assert.False(t, ok)
+	name, _ = h.goBack()
+	assert.Equal(t, "Cos", name)
+	assert.Equal(t, []string{"Plot"}, h.forward)
+}
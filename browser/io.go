@@ -1,28 +1,111 @@
 package browser
 
 import (
-	"bytes"
 	"fmt"
-	"github.com/corywalker/expreduce/expreduce"
-	"github.com/corywalker/expreduce/expreduce/atoms"
-	"github.com/corywalker/expreduce/expreduce/parser"
+	"github.com/wrnrlr/foxtrot/nbx"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
-func Read(s string) {
-	src := parser.ReplaceSyms(s)
-	buf := bytes.NewBufferString(src)
-	kernel := expreduce.NewEvalState()
-	expOut, _ := parser.InterpBuf(buf, "nofile", kernel)
-	fmt.Printf("%v\n", expOut)
-	// get cells
-	ex, _ := expOut.(*atoms.Expression)
-	if ex.HeadStr() == "" {
-		// data box
+// Loaded is the result of opening the notebook at a location.
+type Loaded struct {
+	URL      *url.URL
+	Notebook *nbx.Notebook
+	Err      error
+}
+
+// Remote reports whether the notebook was downloaded, it can not be saved at its URL.
+func (l Loaded) Remote() bool {
+	return l.URL.Scheme == "http" || l.URL.Scheme == "https"
+}
 
-		// rowbox
+// Path is the file of a local notebook, it is empty for a remote notebook.
+func (l Loaded) Path() string {
+	if l.URL.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(l.URL.Path)
+}
 
-		// cell type
+var client = &http.Client{Timeout: 30 * time.Second}
 
-		// cell label
+// IsLocation reports whether s is the location of a notebook rather than a search of the documentation.
+func IsLocation(s string) bool {
+	if strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "file://") {
+		return true
+	}
+	switch path.Ext(s) {
+	case ".nbx", ".cell":
+		return true
+	}
+	return false
+}
+
+// DirURL returns the file URL of the directory dir, relative locations are resolved against it.
+func DirURL(dir string) *url.URL {
+	p := filepath.ToSlash(dir)
+	if !strings.HasSuffix(p, "/") {
+		p += "/"
+	}
+	return &url.URL{Scheme: "file", Path: p}
+}
+
+// Resolve returns the URL of location, a location without a scheme is a path relative to base.
+func Resolve(location string, base *url.URL) (*url.URL, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https", "file":
+		return u, nil
+	case "":
+		return base.ResolveReference(u), nil
+	}
+	return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+}
+
+// Fetch reads the notebook at u, a file URL is read from disk and an http(s) URL is downloaded.
+func Fetch(u *url.URL) (*nbx.Notebook, error) {
+	switch u.Scheme {
+	case "file":
+		return nbx.ReadFile(filepath.FromSlash(u.Path))
+	case "http", "https":
+		resp, err := client.Get(u.String())
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to download %s: %s", u, resp.Status)
+		}
+		return nbx.ReadFrom(resp.Body, path.Base(u.Path))
+	}
+	return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+}
+
+// LocalCopyPath returns a file in dir for a copy of the notebook at u,
+// a number is added to the name when the file already exists.
+func LocalCopyPath(u *url.URL, dir string) string {
+	name := path.Base(u.Path)
+	if name == "/" || name == "." {
+		name = "notebook"
+	}
+	if ext := path.Ext(name); ext != ".nbx" && ext != ".cell" {
+		name += ".nbx"
+	}
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	p := filepath.Join(dir, name)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(p); os.IsNotExist(err) {
+			return p
+		}
+		p = filepath.Join(dir, fmt.Sprintf("%s-%d%s", stem, i, ext))
 	}
 }
@@ -0,0 +1,114 @@
+package browser
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/nbx"
+	"github.com/wrnrlr/foxtrot/theme"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsLocation(t *testing.T) {
+	for s, want := range map[string]bool{
+		"https://example.com/a.nbx": true,
+		"http://example.com/a":      true,
+		"file:///tmp/a.nbx":         true,
+		"notes/a.cell":              true,
+		"../a.nbx":                  true,
+		"solve an equation":         false,
+		"Sin":                       false,
+	} {
+		assert.Equal(t, want, IsLocation(s), s)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	base := DirURL("/home/me/notes")
+	for location, want := range map[string]string{
+		"a.nbx":                     "file:///home/me/notes/a.nbx",
+		"../a.nbx":                  "file:///home/me/a.nbx",
+		"/tmp/a.nbx":                "file:///tmp/a.nbx",
+		"file:///tmp/b.cell":        "file:///tmp/b.cell",
+		"https://example.com/a.nbx": "https://example.com/a.nbx",
+	} {
+		u, err := Resolve(location, base)
+		assert.NoError(t, err)
+		assert.Equal(t, want, u.String(), location)
+	}
+	_, err := Resolve("ftp://example.com/a.nbx", base)
+	assert.Error(t, err)
+}
+
+func testNotebook(t *testing.T) []byte {
+	c := cell.NewCell(cell.Input, "In[1]:=", theme.DefaultStyles())
+	c.SetText("1+1")
+	var buf bytes.Buffer
+	assert.NoError(t, nbx.Write(&buf, cell.Cells{c}))
+	return buf.Bytes()
+}
+
+func TestFetch(t *testing.T) {
+	content := testNotebook(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a.nbx":
+			w.Write(content)
+		case "/b.cell":
+			w.Write([]byte(`Notebook[Cell[BoxData["2+2"], "Input"]]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL + "/a.nbx")
+	nb, err := Fetch(u)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(nb.Cells))
+	assert.Equal(t, "1+1", nb.Cells[0].Text())
+	assert.True(t, Loaded{URL: u}.Remote())
+
+	u, _ = url.Parse(srv.URL + "/b.cell")
+	nb, err = Fetch(u)
+	assert.NoError(t, err)
+	assert.Equal(t, "2+2", nb.Cells[0].Text())
+
+	u, _ = url.Parse(srv.URL + "/missing.nbx")
+	_, err = Fetch(u)
+	assert.Error(t, err)
+}
+
+func TestFetchFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "browser")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a.nbx"), testNotebook(t), 0644))
+
+	u, err := Resolve("a.nbx", DirURL(dir))
+	assert.NoError(t, err)
+	nb, err := Fetch(u)
+	assert.NoError(t, err)
+	assert.Equal(t, "1+1", nb.Cells[0].Text())
+	l := Loaded{URL: u}
+	assert.False(t, l.Remote())
+	assert.Equal(t, filepath.Join(dir, "a.nbx"), l.Path())
+}
+
+func TestLocalCopyPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "browser")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	u, _ := url.Parse("https://example.com/notes/a.nbx")
+	assert.Equal(t, filepath.Join(dir, "a.nbx"), LocalCopyPath(u, dir))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a.nbx"), nil, 0644))
+	assert.Equal(t, filepath.Join(dir, "a-1.nbx"), LocalCopyPath(u, dir))
+	u, _ = url.Parse("https://example.com/")
+	assert.Equal(t, filepath.Join(dir, "notebook.nbx"), LocalCopyPath(u, dir))
+}
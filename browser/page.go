@@ -0,0 +1,133 @@
+package browser
+
+import (
+	"gioui.org/io/pointer"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"github.com/corywalker/expreduce/expreduce/atoms"
+	"github.com/corywalker/expreduce/pkg/expreduceapi"
+	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/kernel"
+	"github.com/wrnrlr/foxtrot/search"
+	"github.com/wrnrlr/foxtrot/theme"
+	"image"
+	"strings"
+)
+
+// page is the documentation of a symbol, it is shown with the same cells as a notebook.
+type page struct {
+	name     string
+	cells    cell.Cells
+	examples []*example
+	list     layout.List
+}
+
+// example is an input cell and its output, clicking it copies the input into the notebook.
+type example struct {
+	in, out cell.Cell
+	button  widget.Button
+}
+
+// newPage makes the page of the symbol with the name from the metadata of its definition.
+func newPage(name string, styles *theme.Styles) *page {
+	p := &page{name: name, list: layout.List{Axis: layout.Vertical}}
+	p.add(cell.H2, name, styles)
+	doc, ok := search.Lookup(name)
+	if !ok {
+		p.add(cell.Paragraph, "There is no documentation for "+name+".", styles)
+		return p
+	}
+	if doc.Usage != "" {
+		p.add(cell.Paragraph, plain(doc.Usage), styles)
+	}
+	if len(doc.Attributes) > 0 {
+		p.add(cell.Paragraph, "Attributes: "+strings.Join(doc.Attributes, ", "), styles)
+	}
+	if len(doc.Examples) > 0 {
+		p.add(cell.H4, "Examples", styles)
+	}
+	for _, ex := range doc.Examples {
+		in := cell.NewCell(cell.Input, "In:", styles)
+		in.SetText(ex.In)
+		var out cell.Cell
+		if ex.Out != "" {
+			out = cell.NewCell(cell.Output, "Out:", styles)
+			out.SetOut(parse(ex.Out))
+		}
+		p.examples = append(p.examples, &example{in: in, out: out})
+	}
+	return p
+}
+
+// plain removes the backquotes that mark code in a usage message.
+func plain(usage string) string {
+	return strings.Replace(usage, "`", "", -1)
+}
+
+func (p *page) add(typ cell.Type, s string, styles *theme.Styles) {
+	c := cell.NewCell(typ, "", styles)
+	c.SetText(s)
+	p.cells = append(p.cells, c)
+}
+
+// parse reads the expression of an example's output, it is kept as a string when it does not parse.
+func parse(s string) expreduceapi.Ex {
+	ex, err := kernel.Parse(s)
+	if err != nil {
+		return atoms.NewString(s)
+	}
+	return ex
+}
+
+// Event returns a CopyEvent when an example was clicked.
+func (p *page) Event(gtx *layout.Context) interface{} {
+	for _, ex := range p.examples {
+		if ex.button.Clicked(gtx) {
+			return CopyEvent{Text: ex.in.Text()}
+		}
+	}
+	return nil
+}
+
+func (p *page) Layout(gtx *layout.Context) {
+	n := len(p.cells)
+	p.list.Layout(gtx, n+len(p.examples), func(i int) {
+		if i < n {
+			p.cells[i].Layout(false, gtx)
+		} else {
+			p.examples[i-n].Layout(gtx)
+		}
+	})
+}
+
+func (ex *example) Layout(gtx *layout.Context) {
+	clickable(gtx, &ex.button, func() {
+		layout.Inset{Bottom: unit.Sp(8)}.Layout(gtx, func() {
+			f := layout.Flex{Axis: layout.Vertical}
+			in := layout.Rigid(func() {
+				ex.in.Layout(false, gtx)
+			})
+			out := layout.Rigid(func() {
+				if ex.out != nil {
+					ex.out.Layout(false, gtx)
+				}
+			})
+			f.Layout(gtx, in, out)
+		})
+	})
+}
+
+// clickable lays out w and puts the button on top of it, so the button receives the clicks on w.
+func clickable(gtx *layout.Context, button *widget.Button, w func()) {
+	width := gtx.Constraints.Width.Max
+	w()
+	dims := gtx.Dimensions
+	var stack op.StackOp
+	stack.Push(gtx.Ops)
+	pointer.Rect(image.Rectangle{Max: image.Point{X: width, Y: dims.Size.Y}}).Add(gtx.Ops)
+	button.Layout(gtx)
+	stack.Pop()
+	gtx.Dimensions = dims
+}
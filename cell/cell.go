@@ -15,11 +15,22 @@ type Cell interface {
 
 	Type() Type
 	Text() string
+	Label() string
+	Prompt() int
+	State() State
+	Err() error
+	Out() expreduceapi.Ex
+	// Version is incremented by every change to the text of the cell.
+	Version() int
 	Focus()
+	Focused() bool
+	ShowCompletions(items []editor.Completion)
 
 	SetText(s string)
 	SetType(s Type)
 	SetLabel(s string)
+	SetPrompt(n int)
+	SetState(s State)
 	SetErr(err error)
 	SetOut(ex expreduceapi.Ex)
 
@@ -36,11 +47,14 @@ type cell struct {
 
 	content string
 	label   string
+	prompt  int
 
 	out expreduceapi.Ex
 
 	input *editor.Editor
 
+	state State
+
 	err    error
 	hide   bool
 	slot   widget.Button
@@ -69,6 +83,10 @@ func (c *cell) SetText(txt string) {
 	c.input.SetText(txt)
 }
 
+func (c cell) Version() int {
+	return c.input.Version()
+}
+
 func (c *cell) SetType(typ Type) {
 	c.typ = typ
 }
@@ -77,13 +95,43 @@ func (c *cell) SetLabel(s string) {
 	c.label = s
 }
 
+func (c cell) Label() string {
+	return c.label
+}
+
+func (c *cell) SetPrompt(n int) {
+	c.prompt = n
+}
+
+// Prompt is the number n of the In[n] or Out[n] label, zero if the cell was never evaluated.
+func (c cell) Prompt() int {
+	return c.prompt
+}
+
+func (c *cell) SetState(s State) {
+	c.state = s
+}
+
+func (c cell) State() State {
+	return c.state
+}
+
 func (c *cell) SetErr(err error) {
 	c.err = err
 }
+
+func (c cell) Err() error {
+	return c.err
+}
+
 func (c *cell) SetOut(ex expreduceapi.Ex) {
 	c.out = ex
 }
 
+func (c cell) Out() expreduceapi.Ex {
+	return c.out
+}
+
 func (c cell) ToEx() *atoms.Expression {
 	return nil
 }
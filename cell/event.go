@@ -1,19 +1,30 @@
 package cell
 
 import (
+	"gioui.org/io/key"
 	"gioui.org/layout"
 	"github.com/wrnrlr/foxtrot/editor"
 )
 
 func (c cell) Event(gtx *layout.Context) interface{} {
 	for _, e := range c.input.Events(gtx) {
-		switch e.(type) {
+		switch e := e.(type) {
 		case editor.SubmitEvent:
 			return EvalEvent{}
+		case editor.AbortEvent:
+			return AbortEvent{}
 		case editor.UpEvent:
 			return FocusPlaceholder{Offset: 0}
 		case editor.DownEvent:
 			return FocusPlaceholder{Offset: 1}
+		case editor.CompleteEvent:
+			if c.typ == Input {
+				return CompleteEvent{Prefix: e.Prefix}
+			}
+		case editor.CommandEvent:
+			if e.Event.Name == "S" {
+				return SaveEvent{As: e.Event.Modifiers.Contain(key.ModShift)}
+			}
 		}
 	}
 	return c.margin.Event(gtx)
@@ -21,5 +32,12 @@ func (c cell) Event(gtx *layout.Context) interface{} {
 
 type FocusPlaceholder struct{ Offset int }
 type EvalEvent struct{}
+type AbortEvent struct{}
+
+// A CompleteEvent asks for the symbols that start with Prefix, they are shown with ShowCompletions.
+type CompleteEvent struct{ Prefix string }
 type SelectFirstCellEvent struct{}
+
+// A SaveEvent asks to save the notebook, to a new file when As is set.
+type SaveEvent struct{ As bool }
 type SelectLastCellEvent struct{}
@@ -1,5 +1,16 @@
 package cell
 
+import "github.com/wrnrlr/foxtrot/editor"
+
 func (c cell) Focus() {
 	c.input.Focus()
 }
+
+// ShowCompletions shows a list of symbols that complete the symbol at the caret.
+func (c cell) ShowCompletions(items []editor.Completion) {
+	c.input.ShowCompletions(items)
+}
+
+func (c cell) Focused() bool {
+	return c.input.Focused()
+}
@@ -17,12 +17,21 @@ func (c cell) Layout(selected bool, gtx *layout.Context) {
 	// Layout Output
 	// Layout margin
 	layout.Inset{Right: unit.Sp(10)}.Layout(gtx, func() {
-		c.margin.Layout(gtx, selected, func() {
+		c.margin.Layout(gtx, selected, c.state, c.unbalanced(), func() {
 			c.cellLayout(gtx)
 		})
 	})
 }
 
+// unbalanced reports whether the brackets of an input or code cell are unbalanced.
+func (c cell) unbalanced() bool {
+	if c.typ != Input && c.typ != Code {
+		return false
+	}
+	_, ok := c.input.Unbalanced()
+	return ok
+}
+
 func (c *cell) cellLayout(gtx *layout.Context) {
 	switch c.Type() {
 	case Input:
@@ -13,6 +13,7 @@ import (
 	"github.com/wrnrlr/foxtrot/util"
 	"github.com/wrnrlr/shape"
 	"image"
+	"image/color"
 )
 
 type Margin struct {
@@ -32,7 +33,8 @@ func (m *Margin) Event(gtx *layout.Context) interface{} {
 	return nil
 }
 
-func (m *Margin) Layout(gtx *layout.Context, checked bool, widget layout.Widget) {
+// Layout lays out the widget with the margin on its right, an unbalanced cell is flagged in the margin.
+func (m *Margin) Layout(gtx *layout.Context, checked bool, state State, unbalanced bool, widget layout.Widget) {
 	dim := gtx.Dimensions
 	marginWidth := gtx.Px(unit.Sp(15))
 	editorWidth := gtx.Constraints.Width.Max - marginWidth
@@ -44,7 +46,7 @@ func (m *Margin) Layout(gtx *layout.Context, checked bool, widget layout.Widget)
 	gtx.Constraints = layout.RigidConstraints(image.Point{X: marginWidth, Y: editorHeight})
 	offset := image.Point{X: editorWidth, Y: 0}
 	op.TransformOp{}.Offset(util.ToPointF(offset)).Add(gtx.Ops)
-	m.layoutMargin(checked, gtx)
+	m.layoutMargin(checked, state, unbalanced, gtx)
 	r := image.Rectangle{Max: image.Point{X: marginWidth, Y: editorHeight}}
 	pointer.Rect(r).Add(gtx.Ops)
 	m.scroller.Add(gtx.Ops)
@@ -54,7 +56,7 @@ func (m *Margin) Layout(gtx *layout.Context, checked bool, widget layout.Widget)
 	gtx.Dimensions = dim
 }
 
-func (m *Margin) layoutMargin(checked bool, gtx *layout.Context) {
+func (m *Margin) layoutMargin(checked bool, state State, unbalanced bool, gtx *layout.Context) {
 	s := float32(gtx.Px(unit.Sp(1)))
 	cs := gtx.Constraints
 	w := float32(cs.Width.Max)
@@ -63,6 +65,7 @@ func (m *Margin) layoutMargin(checked bool, gtx *layout.Context) {
 		a, b := f32.Point{}, f32.Point{w, h}
 		shape.Rectangle{a, b}.Fill(util.SelectedColor, gtx)
 	}
+	m.layoutState(state, unbalanced, w, h, s, gtx)
 	// Todo: Use shape api
 	//margin := float32(s*2)
 	//p1 := f32.Point{X: margin, Y: margin}
@@ -85,3 +88,21 @@ func (m *Margin) layoutMargin(checked bool, gtx *layout.Context) {
 	paint.ColorOp{util.LightGrey}.Add(gtx.Ops)
 	paint.PaintOp{Rect: f32.Rectangle{Max: f32.Point{X: w, Y: h}}}.Add(gtx.Ops)
 }
+
+// layoutState fills the inside of the margin while the cell is waiting for or busy with an evaluation,
+// or when its brackets are unbalanced.
+func (m *Margin) layoutState(state State, unbalanced bool, w, h, s float32, gtx *layout.Context) {
+	var col color.RGBA
+	switch {
+	case state == Queued:
+		col = util.LightGrey
+	case state == Running:
+		col = util.LightBlue
+	case unbalanced:
+		col = util.Red
+	default:
+		return
+	}
+	a, b := f32.Point{X: 4 * s, Y: 4 * s}, f32.Point{X: w - 4*s, Y: h - 4*s}
+	shape.Rectangle{a, b}.Fill(col, gtx)
+}
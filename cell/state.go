@@ -0,0 +1,10 @@
+package cell
+
+// State of the evaluation of a cell.
+type State int
+
+const (
+	Idle State = iota
+	Queued
+	Running
+)
@@ -17,7 +17,7 @@ const (
 	Inline
 )
 
-var CellTypeNames = []string{"Input", "Output", "H1", "H2", "H3", "H4", "H5", "H6", "Paragraph", "Code"}
+var CellTypeNames = []string{"Empty", "Input", "Output", "H1", "H2", "H3", "H4", "H5", "H6", "Paragraph", "Code", "Inline"}
 
 func (d Type) String() string {
 	return CellTypeNames[d]
@@ -30,7 +30,7 @@ func ParseType(s string) Type {
 	case "Output":
 		return Output
 	case "H1":
-		return H2
+		return H1
 	case "H2":
 		return H2
 	case "H3":
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/wrnrlr/foxtrot/nbx"
+	"os"
+	"strings"
+)
+
+// diff prints the cells that differ between two notebooks, the exit status is 1 when they differ.
+//
+//	foxtrot diff [-all] old.nbx new.nbx
+func diff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	all := fs.Bool("all", false, "print the cells that did not change as well")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: foxtrot diff [-all] old.nbx new.nbx\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+	old, err := nbx.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", fs.Arg(0), err)
+		return 2
+	}
+	new, err := nbx.ReadFile(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", fs.Arg(1), err)
+		return 2
+	}
+	changed := false
+	fmt.Printf("--- %s\n+++ %s\n", fs.Arg(0), fs.Arg(1))
+	for _, l := range nbx.DiffLines(old.Cells, new.Cells, nbx.Diff(old.Cells, new.Cells)) {
+		sign := "  "
+		switch l.Op {
+		case nbx.Removed:
+			sign = "- "
+		case nbx.Added:
+			sign = "+ "
+		case nbx.Moved:
+			sign = "> "
+		}
+		if l.Op != nbx.Equal {
+			changed = true
+		} else if !*all {
+			continue
+		}
+		fmt.Println(sign + strings.Replace(l.Text, "\n", "\n"+sign, -1))
+	}
+	if changed {
+		return 1
+	}
+	return 0
+}
+
+// merge combines the changes of two notebooks to a common ancestor, conflicts are marked by cells
+// and give exit status 1. As a git merge driver the result is written to the file of ours.
+//
+//	foxtrot merge [-o out.nbx] [-name notebook.nbx] base.nbx ours.nbx theirs.nbx
+func merge(args []string) int {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	out := fs.String("o", "", "write the merged notebook to this file instead of ours")
+	name := fs.String("name", "", "read and write the files in the format of this file name, like %P of a git merge driver")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: foxtrot merge [-o out.nbx] [-name notebook.nbx] base.nbx ours.nbx theirs.nbx\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 3 {
+		fs.Usage()
+		return 2
+	}
+	if *out == "" {
+		*out = fs.Arg(1)
+	}
+	if *name == "" {
+		*name = fs.Arg(1)
+	}
+	var nbs [3]*nbx.Notebook
+	for i := range nbs {
+		nb, err := nbx.ReadFileAs(fs.Arg(i), *name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", fs.Arg(i), err)
+			return 2
+		}
+		nbs[i] = nb
+	}
+	cells, conflicts := nbx.Merge(nbs[0].Cells, nbs[1].Cells, nbs[2].Cells)
+	count := nbs[1].PromptCount
+	if nbs[2].PromptCount > count {
+		count = nbs[2].PromptCount
+	}
+	if err := nbx.WriteNotebookFileAs(*out, *name, &nbx.Notebook{Cells: cells, PromptCount: count}); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		return 2
+	}
+	if conflicts > 0 {
+		fmt.Fprintf(os.Stderr, "%d conflicting cells in %s\n", conflicts, *out)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/wrnrlr/foxtrot/html"
+	"github.com/wrnrlr/foxtrot/nbx"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// export writes a notebook as a self-contained HTML file.
+//
+//	foxtrot export [-o out.html] notebook.nbx
+func export(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	out := fs.String("o", "", "write the HTML to this file instead of the notebook name with the .html extension")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: foxtrot export [-o out.html] notebook.nbx\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	path := fs.Arg(0)
+	nb, err := nbx.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", path, err)
+		return 1
+	}
+	if *out == "" {
+		*out = strings.TrimSuffix(path, filepath.Ext(path)) + ".html"
+	}
+	if err := html.WriteFile(*out, nb.Cells); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		return 1
+	}
+	return 0
+}
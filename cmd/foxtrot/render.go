@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"gioui.org/font"
+	"gioui.org/font/gofont"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"github.com/wrnrlr/foxtrot/colors"
+	"github.com/wrnrlr/foxtrot/kernel"
+	"github.com/wrnrlr/foxtrot/output"
+	"github.com/wrnrlr/foxtrot/style"
+	"image/png"
+	"os"
+)
+
+// render evaluates an expression and writes its typeset output as a PNG image without a GPU.
+//
+//	foxtrot render [-o out.png] [-scale 2] 'expr'
+func render(args []string) int {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	out := fs.String("o", "out.png", "write the image to this file")
+	scale := fs.Float64("scale", 1.5, "number of pixels per sp")
+	size := fs.Float64("size", 16, "font size in sp")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: foxtrot render [-o out.png] 'expr'\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	ex, err := kernel.NewKernel().Eval(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to evaluate %s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+	gofont.Register()
+	s := style.Style{
+		Font:   text.Font{Size: unit.Sp(float32(*size))},
+		Shaper: font.Default(),
+		Color:  colors.Black,
+	}
+	img := output.Render(ex, s, float32(*scale))
+	file, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		return 1
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		return 1
+	}
+	return 0
+}
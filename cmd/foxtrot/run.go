@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/wrnrlr/foxtrot/kernel"
+	"github.com/wrnrlr/foxtrot/nbx"
+	"github.com/wrnrlr/foxtrot/theme"
+	"os"
+)
+
+// run evaluates all input cells of a notebook without opening a window.
+//
+//	foxtrot run [-o out.nbx] notebook.nbx
+func run(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	out := fs.String("o", "", "write the evaluated notebook to this file instead of overwriting the input")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: foxtrot run [-o out.nbx] notebook.nbx\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	path := fs.Arg(0)
+	nb, err := nbx.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", path, err)
+		return 1
+	}
+	k := kernel.NewKernel()
+	cells, errs := kernel.EvalCells(k, nb.Cells, theme.DefaultStyles())
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+	}
+	if *out == "" {
+		*out = path
+	}
+	nb = &nbx.Notebook{Cells: cells, PromptCount: k.PromptCount()}
+	if err := nbx.WriteNotebookFile(*out, nb); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		return 1
+	}
+	if len(errs) > 0 {
+		return 1
+	}
+	return 0
+}
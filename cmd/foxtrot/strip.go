@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/wrnrlr/foxtrot/nbx"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// strip removes the outputs of notebooks and resets their prompts, without files it is a filter
+// from standard input to standard output, like the clean filter of git.
+//
+//	foxtrot strip [-name notebook.nbx] [notebook.nbx ...]
+func strip(args []string) int {
+	fs := flag.NewFlagSet("strip", flag.ExitOnError)
+	name := fs.String("name", "notebook.nbx", "the format of standard input and output, .nbx or .cell")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: foxtrot strip [-name notebook.nbx] [notebook.nbx ...]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		nb, err := nbx.ReadFrom(os.Stdin, *name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read notebook: %v\n", err)
+			return 1
+		}
+		if err := writeTo(os.Stdout, *name, nbx.Strip(nb)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write notebook: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	status := 0
+	for _, path := range fs.Args() {
+		nb, err := nbx.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", path, err)
+			status = 1
+			continue
+		}
+		if err := nbx.WriteNotebookFile(path, nbx.Strip(nb)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", path, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// writeTo writes a notebook to w in the format of the extension of name.
+func writeTo(w io.Writer, name string, nb *nbx.Notebook) error {
+	if filepath.Ext(name) == ".cell" {
+		return nbx.WriteCells(w, nb.Cells)
+	}
+	return nbx.Encode(w, nb)
+}
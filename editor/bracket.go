@@ -0,0 +1,387 @@
+package editor
+
+import (
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/paint"
+	"github.com/wrnrlr/foxtrot/parser"
+	"image"
+	"strings"
+)
+
+// closing maps the opening brackets to their closing bracket.
+var closing = map[string]string{"[": "]", "{": "}", "(": ")", "<|": "|>"}
+
+func isOpening(t parser.Token) bool {
+	_, ok := closing[t.Val]
+	return t.Kind == parser.Bracket && ok
+}
+
+func isClosing(t parser.Token) bool {
+	return t.Kind == parser.Bracket && !isOpening(t)
+}
+
+// walk calls fn with the absolute position of each token, starting at line first and going backwards
+// when reverse is set, until fn returns false.
+func (h *highlighter) walk(b textBuffer, first int, reverse bool, fn func(pos int, t parser.Token) bool) {
+	for i := first; i >= 0 && i < len(h.lines); {
+		off := b.lineStart(i)
+		tokens := h.lines[i].tokens
+		if reverse {
+			for j := len(tokens) - 1; j >= 0; j-- {
+				if !fn(off+tokens[j].Pos.Offset, tokens[j]) {
+					return
+				}
+			}
+			i--
+		} else {
+			for _, t := range tokens {
+				if !fn(off+t.Pos.Offset, t) {
+					return
+				}
+			}
+			i++
+		}
+	}
+}
+
+// tokenAt returns the token that contains the byte position pos and its absolute position.
+func (h *highlighter) tokenAt(b textBuffer, pos int) (int, parser.Token, bool) {
+	var (
+		tpos int
+		tok  parser.Token
+		ok   bool
+	)
+	h.walk(b, b.lineOf(pos), false, func(p int, t parser.Token) bool {
+		if p+len(t.Val) > pos {
+			tpos, tok, ok = p, t, p <= pos
+			return false
+		}
+		return true
+	})
+	return tpos, tok, ok
+}
+
+// isCommentStart reports whether the comment token at pos starts a comment rather than continue one.
+func (h *highlighter) isCommentStart(b textBuffer, pos int, t parser.Token) bool {
+	line := b.lineOf(pos)
+	return t.Kind == parser.Comment && strings.HasPrefix(t.Val, "(*") && (pos > b.lineStart(line) || h.lines[line].start.Comment == 0)
+}
+
+// commentEnd returns the position after the comment that starts at pos.
+func (h *highlighter) commentEnd(b textBuffer, pos int) (int, bool) {
+	end, closed := pos, false
+	h.walk(b, b.lineOf(pos), false, func(p int, t parser.Token) bool {
+		if p < pos {
+			return true
+		}
+		if p > end || t.Kind != parser.Comment {
+			return false
+		}
+		end = p + len(t.Val)
+		l := h.lines[b.lineOf(p)]
+		last := l.tokens[len(l.tokens)-1]
+		closed = last.Pos.Offset+b.lineStart(b.lineOf(p)) != p || l.end.Comment == 0
+		return !closed
+	})
+	return end, closed
+}
+
+// commentStart returns the start of the comment that ends at end.
+func (h *highlighter) commentStart(b textBuffer, end int) int {
+	start := end
+	h.walk(b, b.lineOf(end-1), true, func(p int, t parser.Token) bool {
+		if p >= end {
+			return true
+		}
+		if p+len(t.Val) < start || t.Kind != parser.Comment {
+			return false
+		}
+		start = p
+		return !h.isCommentStart(b, p, t)
+	})
+	return start
+}
+
+// bracketPair is the position and length of an opening bracket and its closing bracket.
+type bracketPair struct {
+	open, openLen, close, closeLen int
+}
+
+// matchBracket returns the brackets at pos and its matching bracket, the bracket that starts at pos
+// is tried before the bracket that ends at pos.
+func (e *Editor) matchBracket(pos int) (bracketPair, bool) {
+	b := e.buffer()
+	e.highlight.update(b)
+	if p, t, ok := e.highlight.tokenAt(b, pos); ok && p == pos {
+		if m, ok := e.matchToken(p, t); ok {
+			return m, true
+		}
+	}
+	if pos > 0 {
+		if p, t, ok := e.highlight.tokenAt(b, pos-1); ok && p+len(t.Val) == pos {
+			return e.matchToken(p, t)
+		}
+	}
+	return bracketPair{}, false
+}
+
+func (e *Editor) matchToken(pos int, t parser.Token) (bracketPair, bool) {
+	b, h := e.buffer(), &e.highlight
+	switch {
+	case t.Kind == parser.Comment:
+		if h.isCommentStart(b, pos, t) {
+			end, closed := h.commentEnd(b, pos)
+			return bracketPair{pos, 2, end - 2, 2}, closed
+		}
+		if strings.HasSuffix(t.Val, "*)") {
+			end := pos + len(t.Val)
+			if end2, closed := h.commentEnd(b, h.commentStart(b, end)); closed && end2 == end {
+				return bracketPair{h.commentStart(b, end), 2, end - 2, 2}, true
+			}
+		}
+		return bracketPair{}, false
+	case isOpening(t):
+		m, ok := bracketPair{open: pos, openLen: len(t.Val)}, false
+		depth := 0
+		h.walk(b, b.lineOf(pos), false, func(p int, t2 parser.Token) bool {
+			if p < pos {
+				return true
+			}
+			if isOpening(t2) {
+				depth++
+			} else if isClosing(t2) {
+				depth--
+			}
+			if depth == 0 {
+				m.close, m.closeLen, ok = p, len(t2.Val), closing[t.Val] == t2.Val
+				return false
+			}
+			return true
+		})
+		return m, ok
+	case isClosing(t):
+		m, ok := bracketPair{close: pos, closeLen: len(t.Val)}, false
+		depth := 0
+		h.walk(b, b.lineOf(pos), true, func(p int, t2 parser.Token) bool {
+			if p > pos {
+				return true
+			}
+			if isClosing(t2) {
+				depth++
+			} else if isOpening(t2) {
+				depth--
+			}
+			if depth == 0 {
+				m.open, m.openLen, ok = p, len(t2.Val), closing[t2.Val] == t.Val
+				return false
+			}
+			return true
+		})
+		return m, ok
+	}
+	return bracketPair{}, false
+}
+
+// JumpToMatchingBracket moves the caret to the bracket that matches the bracket next to the caret,
+// the caret keeps its side of the bracket.
+func (e *Editor) JumpToMatchingBracket() bool {
+	m, ok := e.matchBracket(e.caret)
+	if !ok {
+		return false
+	}
+	switch e.caret {
+	case m.open:
+		e.caret = m.close
+	case m.open + m.openLen:
+		e.caret = m.close + m.closeLen
+	case m.close:
+		e.caret = m.open
+	default:
+		e.caret = m.open + m.openLen
+	}
+	e.selecting = false
+	e.carXOff = 0
+	return true
+}
+
+// balance caches the result of Unbalanced until the text changes.
+type balance struct {
+	valid, unbalanced bool
+	pos               int
+}
+
+// Unbalanced returns the position of the first bracket that is not closed or that is closed by the wrong bracket,
+// strings and comments that are not closed are unbalanced as well.
+func (e *Editor) Unbalanced() (int, bool) {
+	if !e.balance.valid {
+		b := e.buffer()
+		e.highlight.update(b)
+		pos, ok := e.highlight.unbalanced(b)
+		e.balance = balance{valid: true, unbalanced: ok, pos: pos}
+	}
+	return e.balance.pos, e.balance.unbalanced
+}
+
+func (h *highlighter) unbalanced(b textBuffer) (int, bool) {
+	var (
+		open               []parser.Token
+		opos               []int
+		pos                = -1
+		lastString, lastCm int
+	)
+	h.walk(b, 0, false, func(p int, t parser.Token) bool {
+		switch {
+		case isOpening(t):
+			open, opos = append(open, t), append(opos, p)
+		case isClosing(t):
+			if n := len(open); n == 0 || closing[open[n-1].Val] != t.Val {
+				pos = p
+				return false
+			}
+			open, opos = open[:len(open)-1], opos[:len(opos)-1]
+		case t.Kind == parser.String && strings.HasPrefix(t.Val, `"`):
+			if line := b.lineOf(p); p > b.lineStart(line) || !h.lines[line].start.String {
+				lastString = p
+			}
+		case h.isCommentStart(b, p, t):
+			lastCm = p
+		}
+		return true
+	})
+	if pos >= 0 {
+		return pos, true
+	}
+	if end := h.lines[len(h.lines)-1].end; end.String {
+		return lastString, true
+	} else if end.Comment > 0 {
+		return lastCm, true
+	}
+	if len(opos) > 0 {
+		return opos[len(opos)-1], true
+	}
+	return 0, false
+}
+
+// closers are the positions of the closing brackets and quotes that were inserted by autoClose.
+type closers []int
+
+// edit moves the closers after an edit that replaced n bytes at pos with m bytes,
+// the closers that were replaced are forgotten.
+func (c *closers) edit(pos, n, m int) {
+	kept := (*c)[:0]
+	for _, p := range *c {
+		switch {
+		case p >= pos+n:
+			kept = append(kept, p+m-n)
+		case p < pos:
+			kept = append(kept, p)
+		}
+	}
+	*c = kept
+}
+
+// remove forgets the closer at pos, it reports whether there was one.
+func (c *closers) remove(pos int) bool {
+	for i, p := range *c {
+		if p == pos {
+			*c = append((*c)[:i], (*c)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// autoClose inserts the closing bracket or quote after the caret when s is an opening bracket or quote.
+// Typing a closing bracket or quote in front of one that was inserted that way moves over it instead.
+// It returns false when s has to be inserted as usual.
+func (e *Editor) autoClose(s string) bool {
+	if e.syntax == nil || e.SingleLine {
+		return false
+	}
+	b := e.buffer()
+	e.highlight.update(b)
+	next, _ := b.runeAt(e.caret)
+	prev, _ := b.runeBefore(e.caret)
+	p, t, ok := e.highlight.tokenAt(b, e.caret)
+	inString := ok && p < e.caret && (t.Kind == parser.String || t.Kind == parser.Comment)
+	if !inString && e.caret > 0 {
+		// The caret at the end of an unterminated string.
+		p, t, ok = e.highlight.tokenAt(b, e.caret-1)
+		inString = ok && t.Kind == parser.String && !(len(t.Val) > 1 && strings.HasSuffix(t.Val, `"`) && p+len(t.Val) == e.caret)
+	}
+	switch {
+	case e.hasSelection():
+		c, ok := closing[s]
+		if s == `"` {
+			c, ok = s, true
+		}
+		if !ok {
+			return false
+		}
+		start, end := e.Selection()
+		e.replaceSelection(s+b.slice(start, end)+c, false)
+	case (s == "]" || s == "}" || s == ")" || s == `"`) && string(next) == s && e.closers.remove(e.caret):
+		e.caret++
+		e.carXOff = 0
+	case s == "*" && prev == '(' && next == ')' && !inString:
+		e.insertPair("*", "*")
+	case s == `"` && !inString:
+		e.insertPair(s, s)
+	case closing[s] != "" && s != "<|" && !inString && (e.caret == b.len() || isSpace(next) || strings.ContainsRune("]}),;", next)):
+		e.insertPair(s, closing[s])
+	default:
+		return false
+	}
+	return true
+}
+
+// insertPair inserts open and close and places the caret between them.
+func (e *Editor) insertPair(open, close string) {
+	e.replaceSelection(open+close, true)
+	e.caret -= len(close)
+	e.closers = append(e.closers, e.caret)
+}
+
+// deletePair deletes an empty pair of brackets or quotes around the caret, like the ones inserted by autoClose.
+func (e *Editor) deletePair() bool {
+	if e.syntax == nil || e.caret == 0 {
+		return false
+	}
+	b := e.buffer()
+	pair := b.slice(e.caret-1, clamp(e.caret+1, 0, b.len()))
+	if pair != "[]" && pair != "{}" && pair != "()" && pair != `""` && pair != "**" {
+		return false
+	}
+	e.delete(e.caret-1, 2)
+	return true
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n'
+}
+
+// PaintBrackets paints the background of the bracket next to the caret and its matching bracket.
+func (e *Editor) PaintBrackets(gtx *layout.Context) {
+	if !e.focused || e.syntax == nil || e.hasSelection() {
+		return
+	}
+	m, ok := e.matchBracket(e.caret)
+	if !ok {
+		return
+	}
+	var stack op.StackOp
+	stack.Push(gtx.Ops)
+	for _, r := range [][2]int{{m.open, m.open + m.openLen}, {m.close, m.close + m.closeLen}} {
+		line, _, x1, y := e.layoutPos(r[0])
+		_, _, x2, _ := e.layoutPos(r[1])
+		l := e.lines[line]
+		rect := image.Rectangle{
+			Min: image.Point{X: x1.Floor(), Y: y - l.Ascent.Ceil()},
+			Max: image.Point{X: x2.Ceil(), Y: y + l.Descent.Ceil()},
+		}
+		rect = rect.Add(image.Point{X: -e.scrollOff.X, Y: -e.scrollOff.Y})
+		paint.PaintOp{Rect: toRectF(rect)}.Add(gtx.Ops)
+	}
+	stack.Pop()
+}
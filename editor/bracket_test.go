@@ -0,0 +1,145 @@
+package editor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newCodeEditor(s string) *Editor {
+	e := &Editor{syntax: &SyntaxStyle{}}
+	e.SetText(s)
+	return e
+}
+
+func TestMatchBracket(t *testing.T) {
+	e := newCodeEditor("f[{1, (2)}, \"]\"]\n(* a (* b *)\nc *) x")
+	m, ok := e.matchBracket(1)
+	assert.True(t, ok)
+	assert.Equal(t, bracketPair{1, 1, 15, 1}, m)
+	m, ok = e.matchBracket(10)
+	assert.True(t, ok)
+	assert.Equal(t, bracketPair{2, 1, 9, 1}, m)
+	m, ok = e.matchBracket(16)
+	assert.True(t, ok)
+	assert.Equal(t, bracketPair{1, 1, 15, 1}, m)
+	m, ok = e.matchBracket(17)
+	assert.True(t, ok)
+	assert.Equal(t, bracketPair{17, 2, 32, 2}, m)
+	m, ok = e.matchBracket(34)
+	assert.True(t, ok)
+	assert.Equal(t, bracketPair{17, 2, 32, 2}, m)
+	_, ok = e.matchBracket(4)
+	assert.False(t, ok)
+
+	e = newCodeEditor("f[x}")
+	_, ok = e.matchBracket(1)
+	assert.False(t, ok)
+}
+
+func TestJumpToMatchingBracket(t *testing.T) {
+	e := newCodeEditor("Plot[Sin[x], {x, 0, Pi}]")
+	e.caret = 4
+	assert.True(t, e.JumpToMatchingBracket())
+	assert.Equal(t, 23, e.caret)
+	assert.True(t, e.JumpToMatchingBracket())
+	assert.Equal(t, 4, e.caret)
+	e.caret = 12
+	assert.False(t, e.JumpToMatchingBracket())
+}
+
+func TestUnbalanced(t *testing.T) {
+	for src, want := range map[string]int{
+		"f[x, {y}]":        -1,
+		"f[x, {y]":         7,
+		"f[g[x]":           1,
+		"f[x]]":            4,
+		"\"a]\"":           -1,
+		"x (* a\n(* b *)":  2,
+		"x = \"abc\ndef":   4,
+		"(* [ *) <|a->1|>": -1,
+	} {
+		e := newCodeEditor(src)
+		pos, ok := e.Unbalanced()
+		if want < 0 {
+			assert.False(t, ok, src)
+		} else {
+			assert.True(t, ok, src)
+			assert.Equal(t, want, pos, src)
+		}
+	}
+}
+
+func TestUnbalancedAfterEdit(t *testing.T) {
+	e := newCodeEditor("f[x]")
+	_, ok := e.Unbalanced()
+	assert.False(t, ok)
+	e.caret = 4
+	e.syntax = nil
+	e.append("[")
+	_, ok = e.Unbalanced()
+	assert.True(t, ok)
+}
+
+func TestAutoClose(t *testing.T) {
+	e := newCodeEditor("")
+	for _, s := range []string{"f", "[", "x", ",", " ", "{", "1", "}", "]"} {
+		e.append(s)
+	}
+	assert.Equal(t, "f[x, {1}]", e.Text())
+	assert.Equal(t, 9, e.caret)
+
+	e = newCodeEditor("")
+	for _, s := range []string{"\"", "a", "\"", " ", "("} {
+		e.append(s)
+	}
+	assert.Equal(t, `"a" ()`, e.Text())
+	e.append("*")
+	assert.Equal(t, `"a" (**)`, e.Text())
+	assert.Equal(t, 6, e.caret)
+	e.deletePair()
+	assert.Equal(t, `"a" ()`, e.Text())
+	assert.True(t, e.deletePair())
+	assert.Equal(t, `"a" `, e.Text())
+
+	// No closing bracket is added in front of text or inside a string.
+	e = newCodeEditor("x\"abc")
+	e.caret = 0
+	e.append("[")
+	e.caret = 5
+	e.append("[")
+	e.append("\"")
+	assert.Equal(t, "[x\"ab[\"c", e.Text())
+
+	e = newCodeEditor("a + b")
+	e.Select(0, 5)
+	e.append("(")
+	assert.Equal(t, "(a + b)", e.Text())
+	e.Undo()
+	assert.Equal(t, "a + b", e.Text())
+}
+
+func TestAutoCloseTypeOver(t *testing.T) {
+	// Only the closing brackets and quotes that were inserted by autoClose are typed over.
+	e := newCodeEditor("f[x]")
+	e.caret = 3
+	e.append("]")
+	assert.Equal(t, "f[x]]", e.Text())
+	e = newCodeEditor(`g["a"]`)
+	e.caret = 4
+	e.append(`"`)
+	assert.Equal(t, `g["a""]`, e.Text())
+
+	// The inserted closers move with edits in front of them.
+	e = newCodeEditor("")
+	e.append("[")
+	e.caret = 0
+	e.append("g")
+	e.caret = 2
+	e.append("x")
+	e.append("]")
+	assert.Equal(t, "g[x]", e.Text())
+	assert.Equal(t, 4, e.caret)
+	e.caret = 3
+	e.append("]")
+	assert.Equal(t, "g[x]]", e.Text())
+}
@@ -4,17 +4,41 @@ package editor
 
 import (
 	"fmt"
-	"image"
 	"io"
 	"strings"
 	"unicode/utf8"
 )
 
-const bufferDebug = true
+const bufferDebug = false
+
+// textBuffer stores the text of an Editor, positions are byte offsets into the text
+// and lines are separated by '\n'.
+type textBuffer interface {
+	// Changed reports whether the text has changed since the last call to Changed.
+	Changed() bool
+	String() string
+	len() int
+	// slice returns the text between the byte positions start and end.
+	slice(start, end int) string
+	// replace deletes n bytes at pos and inserts s in their place.
+	replace(pos, n int, s string)
+	runeAt(pos int) (rune, int)
+	runeBefore(pos int) (rune, int)
+	// lineCount returns the number of lines, this is one more than the number of newlines.
+	lineCount() int
+	// lineStart returns the byte position of the start of line.
+	lineStart(line int) int
+	// lineOf returns the line that contains the byte position pos.
+	lineOf(pos int) int
+	// runeOffset converts the byte position pos to the number of runes before it.
+	runeOffset(pos int) int
+	// byteOffset converts a number of runes to a byte position.
+	byteOffset(runes int) int
+}
 
 // editBuffer implements a gap buffer for text editing.
 type editBuffer struct {
-	// caret is the caret position in bytes.
+	// caret is the position of the gap in bytes.
 	caret int
 	// pos is the byte position for Read and ReadRune.
 	pos int
@@ -30,40 +54,19 @@ type editBuffer struct {
 
 const minSpace = 5
 
+func newEditBuffer(s string) textBuffer {
+	e := &editBuffer{}
+	e.replace(0, 0, s)
+	e.changed = false
+	return e
+}
+
 func (e *editBuffer) Changed() bool {
 	c := e.changed
 	e.changed = false
 	return c
 }
 
-func (e *editBuffer) deleteRuneForward() {
-	e.moveGap(0)
-	_, s := utf8.DecodeRune(e.text[e.gapend:])
-	e.gapend += s
-	e.changed = e.changed || s > 0
-	e.dump()
-}
-
-func (e *editBuffer) deleteRune() {
-	e.moveGap(0)
-	_, s := utf8.DecodeLastRune(e.text[:e.gapstart])
-	e.gapstart -= s
-	e.caret -= s
-	e.changed = e.changed || s > 0
-	e.dump()
-}
-
-func (e *editBuffer) deleteRunes(selected image.Point) {
-	l := e.caret + selected.X
-	for i := 0; i < l; i++ {
-		_, s := utf8.DecodeLastRune(e.text[:e.gapstart])
-		e.gapstart -= s
-		e.caret -= s
-		e.changed = e.changed || s > 0
-	}
-	e.dump()
-}
-
 // moveGap moves the gap to the caret position. After returning,
 // the gap is guaranteed to be at least space bytes long.
 func (e *editBuffer) moveGap(space int) {
@@ -139,11 +142,19 @@ func (e *editBuffer) String() string {
 	return b.String()
 }
 
-func (e *editBuffer) prepend(s string) {
+func (e *editBuffer) slice(start, end int) string {
+	return e.String()[start:end]
+}
+
+// replace moves the gap to pos, deletes n bytes after it and inserts s in their place.
+func (e *editBuffer) replace(pos, n int, s string) {
+	e.caret = pos
 	e.moveGap(len(s))
+	e.gapend += n
 	copy(e.text[e.caret:], s)
 	e.gapstart += len(s)
-	e.changed = e.changed || len(s) > 0
+	e.caret += len(s)
+	e.changed = e.changed || n > 0 || len(s) > 0
 	e.dump()
 }
 
@@ -153,18 +164,6 @@ func (e *editBuffer) dump() {
 	}
 }
 
-func (e *editBuffer) moveLeft() {
-	_, s := e.runeBefore(e.caret)
-	e.caret -= s
-	e.dump()
-}
-
-func (e *editBuffer) moveRight() {
-	_, s := e.runeAt(e.caret)
-	e.caret += s
-	e.dump()
-}
-
 func (e *editBuffer) runeBefore(idx int) (rune, int) {
 	if idx > e.gapstart {
 		idx += e.gapLen()
@@ -178,3 +177,42 @@ func (e *editBuffer) runeAt(idx int) (rune, int) {
 	}
 	return utf8.DecodeRune(e.text[idx:])
 }
+
+// The line and rune offsets of the gap buffer scan the whole text,
+// the pieceTable keeps an index for them.
+
+func (e *editBuffer) lineCount() int {
+	return strings.Count(e.String(), "\n") + 1
+}
+
+func (e *editBuffer) lineStart(line int) int {
+	s := e.String()
+	pos := 0
+	for ; line > 0; line-- {
+		i := strings.IndexByte(s[pos:], '\n')
+		if i == -1 {
+			return len(s)
+		}
+		pos += i + 1
+	}
+	return pos
+}
+
+func (e *editBuffer) lineOf(pos int) int {
+	return strings.Count(e.slice(0, pos), "\n")
+}
+
+func (e *editBuffer) runeOffset(pos int) int {
+	return utf8.RuneCountInString(e.slice(0, pos))
+}
+
+func (e *editBuffer) byteOffset(runes int) int {
+	s := e.String()
+	for i := range s {
+		if runes == 0 {
+			return i
+		}
+		runes--
+	}
+	return len(s)
+}
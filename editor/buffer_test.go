@@ -0,0 +1,165 @@
+package editor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+var buffers = map[string]func(string) textBuffer{
+	"gap":   newEditBuffer,
+	"piece": newPieceTable,
+}
+
+func TestBufferReplace(t *testing.T) {
+	for name, newBuffer := range buffers {
+		t.Run(name, func(t *testing.T) {
+			b := newBuffer("Plot[Sin[x], {x, 0, Pi}]")
+			b.replace(5, 6, "Cos[x]")
+			assert.Equal(t, "Plot[Cos[x], {x, 0, Pi}]", b.String())
+			b.replace(12, 0, " ")
+			b.replace(13, 0, "PlotRange")
+			b.replace(22, 0, "->All,")
+			assert.Equal(t, "Plot[Cos[x], PlotRange->All, {x, 0, Pi}]", b.String())
+			b.replace(0, 13, "")
+			assert.Equal(t, "PlotRange->All, {x, 0, Pi}]", b.String())
+			assert.Equal(t, "All", b.slice(11, 14))
+			assert.Equal(t, 27, b.len())
+			assert.True(t, b.Changed())
+			assert.False(t, b.Changed())
+		})
+	}
+}
+
+func TestBufferRunes(t *testing.T) {
+	for name, newBuffer := range buffers {
+		t.Run(name, func(t *testing.T) {
+			b := newBuffer("α+β")
+			b.replace(3, 0, "γ")
+			r, s := b.runeAt(0)
+			assert.Equal(t, 'α', r)
+			assert.Equal(t, 2, s)
+			r, s = b.runeBefore(5)
+			assert.Equal(t, 'γ', r)
+			assert.Equal(t, 2, s)
+			r, s = b.runeAt(5)
+			assert.Equal(t, 'β', r)
+			_, s = b.runeAt(b.len())
+			assert.Equal(t, 0, s)
+			_, s = b.runeBefore(0)
+			assert.Equal(t, 0, s)
+			assert.Equal(t, 3, b.runeOffset(5))
+			assert.Equal(t, 5, b.byteOffset(3))
+			assert.Equal(t, b.len(), b.byteOffset(10))
+		})
+	}
+}
+
+func TestBufferLines(t *testing.T) {
+	for name, newBuffer := range buffers {
+		t.Run(name, func(t *testing.T) {
+			b := newBuffer("a = 1\nb = 2")
+			b.replace(11, 0, "\nc = 3\n")
+			assert.Equal(t, 4, b.lineCount())
+			assert.Equal(t, 0, b.lineStart(0))
+			assert.Equal(t, 6, b.lineStart(1))
+			assert.Equal(t, 12, b.lineStart(2))
+			assert.Equal(t, 18, b.lineStart(3))
+			assert.Equal(t, 18, b.lineStart(9))
+			assert.Equal(t, 0, b.lineOf(5))
+			assert.Equal(t, 1, b.lineOf(6))
+			assert.Equal(t, 2, b.lineOf(14))
+			assert.Equal(t, 3, b.lineOf(18))
+		})
+	}
+}
+
+// TestPieceTableRandom compares the piece table with a string after random edits,
+// the text is large enough to span multiple blocks of the rune index.
+func TestPieceTableRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	words := []string{"x", "→", "Sin[", "]\n", "αβγ", "{1, 2}", "\n\n"}
+	want := strings.Repeat("f[x_] := x^2 → ∞\n", 400)
+	b := newPieceTable(want)
+	for i := 0; i < 2000; i++ {
+		pos := b.byteOffset(r.Intn(b.runeOffset(b.len()) + 1))
+		n := 0
+		if r.Intn(3) == 0 {
+			n = len(b.slice(pos, b.byteOffset(b.runeOffset(pos)+r.Intn(8))))
+		}
+		s := words[r.Intn(len(words))]
+		b.replace(pos, n, s)
+		want = want[:pos] + s + want[pos+n:]
+	}
+	assert.Equal(t, want, b.String())
+	for _, pos := range []int{0, 1, 1000, 4097, len(want) / 2, len(want)} {
+		assert.Equal(t, strings.Count(want[:pos], "\n"), b.lineOf(pos))
+		assert.Equal(t, len([]rune(want[:pos])), b.runeOffset(pos))
+	}
+	lines := strings.Split(want, "\n")
+	assert.Equal(t, len(lines), b.lineCount())
+	pos := 0
+	for i, l := range lines {
+		assert.Equal(t, pos, b.lineStart(i))
+		pos += len(l) + 1
+	}
+}
+
+// largeCell is a multi-megabyte code cell, like data that was pasted into a notebook.
+var largeCell = strings.Repeat("data = {1.5, 2.25, \"π\", {x, y}};\n", 100000)
+
+func BenchmarkTyping(b *testing.B) {
+	for name, newBuffer := range buffers {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				buf := newBuffer(largeCell)
+				pos := buf.lineStart(50000)
+				for j := 0; j < 1000; j++ {
+					buf.replace(pos, 0, "x")
+					pos++
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPaste(b *testing.B) {
+	for name, newBuffer := range buffers {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				buf := newBuffer("")
+				for j := 0; j < 10; j++ {
+					buf.replace(buf.len()/2, 0, largeCell[:len(largeCell)/10])
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkLineStart(b *testing.B) {
+	for name, newBuffer := range buffers {
+		b.Run(name, func(b *testing.B) {
+			buf := newBuffer(largeCell)
+			for j := 0; j < 100; j++ {
+				buf.replace(buf.lineStart(j*1000), 0, "(* edit *)")
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				buf.lineStart(i % 100000)
+			}
+		})
+	}
+}
+
+func BenchmarkRuneOffset(b *testing.B) {
+	for name, newBuffer := range buffers {
+		b.Run(name, func(b *testing.B) {
+			buf := newBuffer(largeCell)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				buf.byteOffset(buf.runeOffset((i * 7919) % buf.len()))
+			}
+		})
+	}
+}
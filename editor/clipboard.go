@@ -0,0 +1,75 @@
+package editor
+
+import (
+	"github.com/atotto/clipboard"
+	"log"
+)
+
+// Selection returns the byte positions of the start and end of the selected text,
+// start equals end when nothing is selected.
+func (e *Editor) Selection() (start, end int) {
+	if !e.hasSelection() {
+		return e.caret, e.caret
+	}
+	if e.anchor < e.caret {
+		return e.anchor, e.caret
+	}
+	return e.caret, e.anchor
+}
+
+// Select selects the text between the byte positions start and end, the caret is placed at end.
+func (e *Editor) Select(start, end int) {
+	e.anchor = clamp(start, 0, e.Len())
+	e.caret = clamp(end, 0, e.Len())
+	e.selecting = true
+	e.carXOff = 0
+}
+
+// SelectedText returns the selected text.
+func (e *Editor) SelectedText() string {
+	return e.buffer().slice(e.Selection())
+}
+
+// Copy returns the selected text so it can be written to the clipboard.
+func (e *Editor) Copy() string {
+	return e.SelectedText()
+}
+
+// Cut deletes the selected text and returns it.
+func (e *Editor) Cut() string {
+	s := e.SelectedText()
+	if s != "" {
+		e.deleteSelection()
+	}
+	return s
+}
+
+// Paste replaces the selected text with s, a paste is undone in a single step.
+func (e *Editor) Paste(s string) {
+	e.replaceSelection(s, false)
+}
+
+func writeClipboard(s string) {
+	if err := clipboard.WriteAll(s); err != nil {
+		log.Printf("failed to write clipboard: %v", err)
+	}
+}
+
+func readClipboard() (string, bool) {
+	s, err := clipboard.ReadAll()
+	if err != nil {
+		log.Printf("failed to read clipboard: %v", err)
+		return "", false
+	}
+	return s, s != ""
+}
+
+func clamp(n, min, max int) int {
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
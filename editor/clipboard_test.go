@@ -0,0 +1,46 @@
+package editor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCopy(t *testing.T) {
+	e := &Editor{}
+	e.SetText("Sin[x] + Cos[x]")
+	assert.Equal(t, "", e.Copy())
+	e.Select(9, 6)
+	start, end := e.Selection()
+	assert.Equal(t, 6, start)
+	assert.Equal(t, 9, end)
+	assert.Equal(t, " + ", e.Copy())
+	assert.Equal(t, "Sin[x] + Cos[x]", e.Text())
+}
+
+func TestCutPaste(t *testing.T) {
+	e := &Editor{}
+	e.SetText("Sin[x] + Cos[x]")
+	e.Select(0, 6)
+	s := e.Cut()
+	assert.Equal(t, "Sin[x]", s)
+	assert.Equal(t, " + Cos[x]", e.Text())
+	e.Select(9, 9)
+	e.Paste(" - " + s)
+	assert.Equal(t, " + Cos[x] - Sin[x]", e.Text())
+	assert.Equal(t, 18, e.caret)
+	e.Select(0, 3)
+	e.append("2")
+	assert.Equal(t, "2Cos[x] - Sin[x]", e.Text())
+	assert.True(t, e.Undo())
+	assert.Equal(t, " + Cos[x] - Sin[x]", e.Text())
+	assert.True(t, e.Undo())
+	assert.True(t, e.Undo())
+	assert.Equal(t, "Sin[x] + Cos[x]", e.Text())
+}
+
+func TestPasteSingleLine(t *testing.T) {
+	e := &Editor{SingleLine: true}
+	e.SetText("")
+	e.Paste("a\nb")
+	assert.Equal(t, "ab", e.Text())
+}
@@ -0,0 +1,176 @@
+package editor
+
+import (
+	"gioui.org/io/key"
+	"time"
+	"unicode"
+)
+
+// Completion is a symbol that can be inserted at the caret.
+type Completion struct {
+	Name string
+	// Usage explains what the symbol does.
+	Usage string
+	// Function is set when the symbol is called with arguments,
+	// brackets are inserted after the name of a function.
+	Function bool
+}
+
+// A CompleteEvent is generated when the completions for the prefix of the symbol
+// in front of the caret are requested, they are shown with ShowCompletions.
+type CompleteEvent struct {
+	Prefix string
+}
+
+// maxCompletions is the number of completions that are visible at the same time.
+const maxCompletions = 8
+
+type completion struct {
+	items    []Completion
+	selected int
+	// start is the byte position of the prefix that is completed.
+	start  int
+	prefix string
+	// at is the time of a delayed request for completions.
+	at time.Time
+	// delay is the time after typing until the completions are requested, zero disables it.
+	delay time.Duration
+}
+
+// ShowCompletions shows items in a list below the text, the selected item replaces the prefix when
+// it is accepted with enter or tab. An empty list of items closes the completions.
+func (e *Editor) ShowCompletions(items []Completion) {
+	if len(items) == 0 {
+		e.closeCompletions()
+		return
+	}
+	e.completion.items = items
+	e.completion.selected = 0
+	e.completion.start, e.completion.prefix = e.prefix()
+}
+
+// Completing reports whether the list of completions is shown.
+func (e *Editor) Completing() bool {
+	return len(e.completion.items) > 0
+}
+
+// visibleCompletions returns the part of the completions that is shown and the index of the selected one.
+func (e *Editor) visibleCompletions() ([]Completion, int) {
+	items, sel := e.completion.items, e.completion.selected
+	first := 0
+	if sel >= maxCompletions {
+		first = sel - maxCompletions + 1
+	}
+	last := first + maxCompletions
+	if last > len(items) {
+		last = len(items)
+	}
+	return items[first:last], sel - first
+}
+
+func (e *Editor) closeCompletions() {
+	e.completion.items = nil
+	e.completion.at = time.Time{}
+}
+
+// prefix returns the start and the text of the symbol in front of the caret.
+func (e *Editor) prefix() (int, string) {
+	b := e.buffer()
+	start := e.caret
+	for {
+		r, s := b.runeBefore(start)
+		if s == 0 || !isSymbolRune(r) {
+			break
+		}
+		start -= s
+	}
+	// A symbol does not start with a digit.
+	for r, s := b.runeAt(start); start < e.caret && unicode.IsDigit(r); r, s = b.runeAt(start) {
+		start += s
+	}
+	return start, b.slice(start, e.caret)
+}
+
+func isSymbolRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '$' || r == '`'
+}
+
+func (e *Editor) requestCompletions() {
+	e.completion.at = time.Time{}
+	e.completion.start, e.completion.prefix = e.prefix()
+	e.events = append(e.events, CompleteEvent{Prefix: e.completion.prefix})
+}
+
+// typed schedules a request for completions after s was typed.
+func (e *Editor) typed(now time.Time, s string) {
+	e.completion.at = time.Time{}
+	if e.completion.delay == 0 || e.Completing() {
+		return
+	}
+	for _, r := range s {
+		if !isSymbolRune(r) {
+			return
+		}
+	}
+	e.completion.at = now.Add(e.completion.delay)
+}
+
+// updateCompletions requests the completions again when the prefix changed,
+// they are closed when the caret moved away from the symbol.
+func (e *Editor) updateCompletions(now time.Time) {
+	if at := e.completion.at; !at.IsZero() && !now.Before(at) {
+		if _, prefix := e.prefix(); prefix != "" && e.focused {
+			e.requestCompletions()
+		} else {
+			e.completion.at = time.Time{}
+		}
+	}
+	if !e.Completing() {
+		return
+	}
+	start, prefix := e.prefix()
+	if start != e.completion.start || !e.focused {
+		e.closeCompletions()
+	} else if prefix != e.completion.prefix {
+		e.requestCompletions()
+	}
+}
+
+// completionKey handles the keys that select and accept completions,
+// it reports whether the key was used.
+func (e *Editor) completionKey(k key.Event) bool {
+	if !e.Completing() || k.Modifiers != 0 {
+		return false
+	}
+	n := len(e.completion.items)
+	switch k.Name {
+	case key.NameUpArrow:
+		e.completion.selected = (e.completion.selected + n - 1) % n
+	case key.NameDownArrow:
+		e.completion.selected = (e.completion.selected + 1) % n
+	case key.NameReturn, key.NameEnter, key.NameTab:
+		e.complete(e.completion.items[e.completion.selected])
+	case key.NameEscape:
+		e.closeCompletions()
+	default:
+		return false
+	}
+	return true
+}
+
+// complete replaces the prefix with c, the caret is placed between the brackets of a function.
+func (e *Editor) complete(c Completion) {
+	e.closeCompletions()
+	start, _ := e.prefix()
+	s := c.Name
+	next, _ := e.buffer().runeAt(e.caret)
+	brackets := c.Function && next != '['
+	if brackets {
+		s += "[]"
+	}
+	e.Select(start, e.caret)
+	e.replaceSelection(s, false)
+	if brackets {
+		e.caret--
+	}
+}
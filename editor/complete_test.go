@@ -0,0 +1,92 @@
+package editor
+
+import (
+	"gioui.org/io/key"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var testCompletions = []Completion{
+	{Name: "Sin", Usage: "Sin[x] is the sine of x.", Function: true},
+	{Name: "SinIntegral", Function: true},
+	{Name: "Sinc", Function: true},
+}
+
+func TestPrefix(t *testing.T) {
+	e := &Editor{}
+	for _, s := range []string{"f[", "Plot[1 + Si", "x2", "2x", "System`Pl", "$Ver", "a "} {
+		e.SetText(s)
+		e.caret = e.Len()
+		_, prefix := e.prefix()
+		assert.Equal(t, map[string]string{
+			"f[": "", "Plot[1 + Si": "Si", "x2": "x2", "2x": "x", "System`Pl": "System`Pl", "$Ver": "$Ver", "a ": "",
+		}[s], prefix, s)
+	}
+}
+
+func TestCompleteFunction(t *testing.T) {
+	e := &Editor{focused: true}
+	e.SetText("Plot[Si, {x, 0, 1}]")
+	e.caret = 7
+	e.requestCompletions()
+	assert.Equal(t, []EditorEvent{CompleteEvent{Prefix: "Si"}}, e.events)
+	e.ShowCompletions(testCompletions)
+	assert.True(t, e.Completing())
+	assert.True(t, e.completionKey(key.Event{Name: key.NameDownArrow}))
+	assert.True(t, e.completionKey(key.Event{Name: key.NameUpArrow}))
+	assert.True(t, e.completionKey(key.Event{Name: key.NameUpArrow}))
+	assert.Equal(t, 2, e.completion.selected)
+	assert.False(t, e.completionKey(key.Event{Name: key.NameLeftArrow}))
+	assert.True(t, e.completionKey(key.Event{Name: key.NameReturn}))
+	assert.False(t, e.Completing())
+	assert.Equal(t, "Plot[Sinc[], {x, 0, 1}]", e.Text())
+	assert.Equal(t, 10, e.caret)
+	e.Undo()
+	assert.Equal(t, "Plot[Si, {x, 0, 1}]", e.Text())
+}
+
+func TestCompleteSymbol(t *testing.T) {
+	e := &Editor{focused: true}
+	e.SetText("N[P]")
+	e.caret = 3
+	e.ShowCompletions([]Completion{{Name: "Pi"}})
+	e.completionKey(key.Event{Name: key.NameTab})
+	assert.Equal(t, "N[Pi]", e.Text())
+	assert.Equal(t, 4, e.caret)
+
+	e.SetText("Si[x]")
+	e.caret = 2
+	e.ShowCompletions(testCompletions)
+	e.completionKey(key.Event{Name: key.NameEnter})
+	assert.Equal(t, "Sin[x]", e.Text())
+}
+
+func TestUpdateCompletions(t *testing.T) {
+	now := time.Now()
+	e := &Editor{focused: true}
+	e.completion.delay = time.Second
+	e.append("S")
+	e.typed(now, "S")
+	e.updateCompletions(now)
+	assert.Empty(t, e.events)
+	e.updateCompletions(now.Add(time.Second))
+	assert.Equal(t, []EditorEvent{CompleteEvent{Prefix: "S"}}, e.events)
+
+	e.events = nil
+	e.ShowCompletions(testCompletions)
+	e.append("i")
+	e.typed(now, "i")
+	e.updateCompletions(now)
+	assert.Equal(t, []EditorEvent{CompleteEvent{Prefix: "Si"}}, e.events)
+
+	e.append("[")
+	e.updateCompletions(now)
+	assert.False(t, e.Completing())
+
+	e.events = nil
+	e.append(" ")
+	e.typed(now, " ")
+	e.updateCompletions(now.Add(time.Second))
+	assert.Empty(t, e.events)
+}
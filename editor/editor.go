@@ -38,7 +38,15 @@ type Editor struct {
 	font         text.Font
 	blinkStart   time.Time
 	focused      bool
-	rr           editBuffer
+	rr           textBuffer
+	version      int
+	caret        int
+	history      history
+	highlight    highlighter
+	balance      balance
+	closers      closers
+	syntax       *SyntaxStyle
+	completion   completion
 	maxWidth     int
 	viewSize     image.Point
 	valid        bool
@@ -54,9 +62,10 @@ type Editor struct {
 	// position when moving between lines.
 	carXOff fixed.Int26_6
 
-	// is the offset of the select caret
-	// position when selecting text
-	selected *image.Point
+	// anchor is the byte position where the selection starts when selecting is set,
+	// the selected text lies between the anchor and the caret.
+	anchor    int
+	selecting bool
 
 	// Track the number of lines and length of current line
 	lineCount, lineWidth, carLine, carCol int
@@ -81,12 +90,14 @@ type ChangeEvent struct{}
 
 type SubmitEvent struct{}
 
+// An AbortEvent is generated when the evaluation of the cell should be stopped.
+type AbortEvent struct{}
+
 type UpEvent struct{}
 
 type DownEvent struct{}
 
-// A CommandEvent is generated for when moving the cursor
-// and when command keys like enter and arrows-keys is pressed
+// A CommandEvent is generated for command keys that the editor does not handle itself, like Cmd+S
 type CommandEvent struct {
 	Event key.Event
 }
@@ -120,6 +131,7 @@ func (e *Editor) Events(gtx *layout.Context) []EditorEvent {
 func (e *Editor) processEvents(gtx *layout.Context) {
 	e.processPointer(gtx)
 	e.processKey(gtx)
+	e.updateCompletions(gtx.Now())
 }
 
 func (e *Editor) processPointer(gtx *layout.Context) {
@@ -147,9 +159,7 @@ func (e *Editor) processPointer(gtx *layout.Context) {
 		case evt.Type == gesture.TypePress && evt.Source == pointer.Mouse,
 			evt.Type == gesture.TypeClick && evt.Source == pointer.Touch:
 			e.blinkStart = gtx.Now()
-			if e.selected == nil && evt.Modifiers.Contain(key.ModShift) {
-				e.selected = &image.Point{X: e.carCol, Y: e.carLine}
-			}
+			e.extendSelection(evt.Modifiers.Contain(key.ModShift))
 			p := image.Point{
 				X: int(math.Round(float64(evt.Position.X))),
 				Y: int(math.Round(float64(evt.Position.Y)))}
@@ -175,9 +185,52 @@ func (e *Editor) processKey(gtx *layout.Context) {
 			if !e.focused {
 				break
 			}
+			e.completion.at = time.Time{}
+			if e.completionKey(ke) {
+				e.caretScroll = true
+				e.CaretLine()
+				break
+			}
 			if (ke.Name == key.NameEnter || ke.Name == key.NameReturn) && ke.Modifiers.Contain(key.ModShift) {
 				e.events = append(e.events, SubmitEvent{})
 				return
+			} else if ke.Name == "." && ke.Modifiers.Contain(key.ModCommand) {
+				e.events = append(e.events, AbortEvent{})
+				return
+			} else if ke.Name == "Z" && ke.Modifiers.Contain(key.ModCommand) {
+				if ke.Modifiers.Contain(key.ModShift) {
+					e.Redo()
+				} else {
+					e.Undo()
+				}
+				e.caretScroll = true
+				e.CaretLine()
+			} else if ke.Name == "K" && ke.Modifiers.Contain(key.ModCommand) {
+				e.requestCompletions()
+			} else if ke.Name == "S" && ke.Modifiers.Contain(key.ModCommand) {
+				e.events = append(e.events, CommandEvent{Event: ke})
+				return
+			} else if ke.Name == "B" && ke.Modifiers.Contain(key.ModCommand) {
+				if e.JumpToMatchingBracket() {
+					e.caretScroll = true
+					e.CaretLine()
+				}
+			} else if ke.Name == "C" && ke.Modifiers.Contain(key.ModCommand) {
+				if e.hasSelection() {
+					writeClipboard(e.Copy())
+				}
+			} else if ke.Name == "X" && ke.Modifiers.Contain(key.ModCommand) {
+				if e.hasSelection() {
+					writeClipboard(e.Cut())
+					e.caretScroll = true
+					e.CaretLine()
+				}
+			} else if ke.Name == "V" && ke.Modifiers.Contain(key.ModCommand) {
+				if s, ok := readClipboard(); ok {
+					e.Paste(s)
+					e.caretScroll = true
+					e.CaretLine()
+				}
 			} else if ke.Name == key.NameUpArrow && e.carLine == 0 {
 				e.events = append(e.events, UpEvent{})
 			} else if ke.Name == key.NameLeftArrow && e.carLine == 0 && e.carCol == 0 {
@@ -198,9 +251,10 @@ func (e *Editor) processKey(gtx *layout.Context) {
 			e.caretScroll = true
 			e.scroller.Stop()
 			e.append(ke.Text)
+			e.typed(gtx.Now(), ke.Text)
 			e.CaretLine()
 		}
-		if e.rr.Changed() {
+		if e.buffer().Changed() {
 			e.events = append(e.events, ChangeEvent{})
 		}
 	}
@@ -211,6 +265,11 @@ func (e *Editor) Focus() {
 	e.requestFocus = true
 }
 
+// Focused reports whether the Editor has the input focus.
+func (e *Editor) Focused() bool {
+	return e.focused
+}
+
 func (e *Editor) PaintText(gtx *layout.Context) {
 	clip := textPadding(e.lines)
 	clip.Max = clip.Max.Add(e.viewSize)
@@ -218,6 +277,9 @@ func (e *Editor) PaintText(gtx *layout.Context) {
 		var stack op.StackOp
 		stack.Push(gtx.Ops)
 		op.TransformOp{}.Offset(shape.offset).Add(gtx.Ops)
+		if shape.color.A != 0 {
+			paint.ColorOp{Color: shape.color}.Add(gtx.Ops)
+		}
 		shape.clip.Add(gtx.Ops)
 		paint.PaintOp{Rect: toRectF(clip).Sub(shape.offset)}.Add(gtx.Ops)
 		stack.Pop()
@@ -259,21 +321,80 @@ func (e *Editor) PaintCaret(gtx *layout.Context) {
 	stack.Pop()
 }
 
+// PaintSelection paints the background of the selected text.
+func (e *Editor) PaintSelection(gtx *layout.Context) {
+	if !e.hasSelection() {
+		return
+	}
+	start, end := e.Selection()
+	startLine, _, startX, _ := e.layoutPos(start)
+	endLine, _, endX, _ := e.layoutPos(end)
+	var stack op.StackOp
+	stack.Push(gtx.Ops)
+	var (
+		prevDesc fixed.Int26_6
+		y        int
+	)
+	for i := 0; i <= endLine; i++ {
+		l := e.lines[i]
+		y += (prevDesc + l.Ascent).Ceil()
+		prevDesc = l.Descent
+		if i < startLine {
+			continue
+		}
+		left := align(e.Alignment, l.Width, e.viewSize.X)
+		right := left + l.Width
+		if i == startLine {
+			left = startX
+		}
+		if i == endLine {
+			right = endX
+		}
+		r := image.Rectangle{
+			Min: image.Point{X: left.Floor(), Y: y - l.Ascent.Ceil()},
+			Max: image.Point{X: right.Ceil(), Y: y + l.Descent.Ceil()},
+		}
+		r = r.Add(image.Point{X: -e.scrollOff.X, Y: -e.scrollOff.Y})
+		paint.PaintOp{Rect: toRectF(r)}.Add(gtx.Ops)
+	}
+	stack.Pop()
+}
+
 // Len is the length of the editor contents.
 func (e *Editor) Len() int {
-	return e.rr.len()
+	return e.buffer().len()
 }
 
 // Text returns the contents of the editor.
 func (e *Editor) Text() string {
-	return e.rr.String()
+	return e.buffer().String()
 }
 
-// SetText replaces the contents of the editor.
+// SetText replaces the contents of the editor and clears the undo history.
 func (e *Editor) SetText(s string) {
-	e.rr = editBuffer{}
+	e.rr = newPieceTable(s)
+	e.caret = 0
+	e.history = history{}
+	e.highlight = highlighter{}
+	e.balance = balance{}
+	e.closers = nil
+	e.selecting = false
 	e.carXOff = 0
-	e.prepend(s)
+	e.version++
+	e.invalidate()
+}
+
+// Version is incremented by every change to the text.
+func (e *Editor) Version() int {
+	return e.version
+}
+
+// buffer returns the text of the editor, an editor starts with an empty piece table.
+func (e *Editor) buffer() textBuffer {
+	if e.rr == nil {
+		e.rr = newPieceTable("")
+	}
+	return e.rr
 }
 
 func (e *Editor) scrollBounds() image.Rectangle {
@@ -333,7 +454,7 @@ func (e *Editor) moveCoord(c unit.Converter, pos image.Point) {
 }
 
 func (e *Editor) layoutText(c unit.Converter, s *text.Shaper, font text.Font) ([]text.Line, layout.Dimensions) {
-	txt := e.rr.String()
+	txt := e.buffer().String()
 	opts := text.LayoutOptions{MaxWidth: e.maxWidth}
 	textLayout := s.Layout(c, font, txt, opts)
 	lines := textLayout.Lines
@@ -354,6 +475,11 @@ func (e *Editor) layoutText(c unit.Converter, s *text.Shaper, font text.Font) ([
 }
 
 func (e *Editor) LayoutCaret() (carLine, carCol int, x fixed.Int26_6, y int) {
+	return e.layoutPos(e.caret)
+}
+
+// layoutPos returns the line, column and coordinates of byte position pos.
+func (e *Editor) layoutPos(pos int) (carLine, carCol int, x fixed.Int26_6, y int) {
 	var idx int
 	var prevDesc fixed.Int26_6
 loop:
@@ -361,10 +487,10 @@ loop:
 		l := e.lines[carLine]
 		y += (prevDesc + l.Ascent).Ceil()
 		prevDesc = l.Descent
-		if carLine == len(e.lines)-1 || idx+len(l.Text.String) > e.rr.caret {
+		if carLine == len(e.lines)-1 || idx+len(l.Text.String) > pos {
 			str := l.Text.String
 			for _, adv := range l.Text.Advances {
-				if idx == e.rr.caret {
+				if idx == pos {
 					break loop
 				}
 				x += adv
@@ -385,32 +511,67 @@ func (e *Editor) invalidate() {
 	e.valid = false
 }
 
+// replace replaces n bytes at pos with s, the lines that changed are highlighted again.
+func (e *Editor) replace(pos, n int, s string) {
+	b := e.buffer()
+	first, last := b.lineOf(pos), b.lineOf(pos+n)
+	b.replace(pos, n, s)
+	e.highlight.edit(first, last-first+1, b.lineOf(pos+len(s))-first+1)
+	e.closers.edit(pos, n, len(s))
+	e.balance.valid = false
+	e.version++
+}
+
 func (e *Editor) deleteRune() {
-	e.rr.deleteRune()
-	e.carXOff = 0
-	e.invalidate()
+	_, n := e.buffer().runeBefore(e.caret)
+	e.delete(e.caret-n, n)
 }
 
 func (e *Editor) deleteRuneForward() {
-	e.rr.deleteRuneForward()
+	_, n := e.buffer().runeAt(e.caret)
+	e.delete(e.caret, n)
+}
+
+// delete removes n bytes at pos, the deletion is part of a run of typing.
+func (e *Editor) delete(pos, n int) {
+	if n == 0 {
+		return
+	}
+	e.history.record(edit{pos: pos, deleted: e.buffer().slice(pos, pos+n), caret: e.caret, typing: true})
+	e.replace(pos, n, "")
+	e.caret = pos
 	e.carXOff = 0
 	e.invalidate()
 }
 
+// append inserts s at the caret, replacing the selected text.
 func (e *Editor) append(s string) {
-	if e.SingleLine {
-		s = strings.ReplaceAll(s, "\n", "")
+	if e.autoClose(s) {
+		return
 	}
-	e.prepend(s)
-	e.rr.caret += len(s)
+	e.replaceSelection(s, true)
 }
 
-func (e *Editor) prepend(s string) {
-	e.rr.prepend(s)
+// replaceSelection replaces the selected text with s and moves the caret after it,
+// typing is set when s was typed rather than pasted.
+func (e *Editor) replaceSelection(s string, typing bool) {
+	if e.SingleLine {
+		s = strings.ReplaceAll(s, "\n", "")
+	}
+	start, end := e.Selection()
+	e.history.record(edit{pos: start, deleted: e.buffer().slice(start, end), inserted: s, caret: e.caret, typing: typing && start == end})
+	e.replace(start, end-start, s)
+	e.caret = start + len(s)
+	e.selecting = false
 	e.carXOff = 0
 	e.invalidate()
 }
 
+// deleteSelection deletes the selected text.
+func (e *Editor) deleteSelection() {
+	e.replaceSelection("", false)
+}
+
 func (e *Editor) movePages(pages int) {
 	_, _, carX, carY := e.LayoutCaret()
 	y := carY + pages*e.viewSize.Y
@@ -445,18 +606,18 @@ func (e *Editor) moveToLine(carX fixed.Int26_6, carLine2 int) fixed.Int26_6 {
 	}
 	// Move to start of line.
 	for i := carCol - 1; i >= 0; i-- {
-		_, s := e.rr.runeBefore(e.rr.caret)
-		e.rr.caret -= s
+		_, s := e.buffer().runeBefore(e.caret)
+		e.caret -= s
 	}
 	if carLine2 != carLine {
 		// Move to start of line2.
 		if carLine2 > carLine {
 			for i := carLine; i < carLine2; i++ {
-				e.rr.caret += len(e.lines[i].Text.String)
+				e.caret += len(e.lines[i].Text.String)
 			}
 		} else {
 			for i := carLine - 1; i >= carLine2; i-- {
-				e.rr.caret -= len(e.lines[i].Text.String)
+				e.caret -= len(e.lines[i].Text.String)
 			}
 		}
 	}
@@ -477,30 +638,30 @@ func (e *Editor) moveToLine(carX fixed.Int26_6, carLine2 int) fixed.Int26_6 {
 			break
 		}
 		carX2 += adv
-		_, s := e.rr.runeAt(e.rr.caret)
-		e.rr.caret += s
+		_, s := e.buffer().runeAt(e.caret)
+		e.caret += s
 	}
 	return carX - carX2
 }
 
 func (e *Editor) moveLeft() {
-	e.rr.moveLeft()
+	_, s := e.buffer().runeBefore(e.caret)
+	e.caret -= s
 	e.carXOff = 0
-	e.selected = nil
 }
 
 func (e *Editor) moveRight() {
-	e.rr.moveRight()
+	_, s := e.buffer().runeAt(e.caret)
+	e.caret += s
 	e.carXOff = 0
-	e.selected = nil
 }
 
 func (e *Editor) moveStart() {
 	carLine, carCol, x, _ := e.LayoutCaret()
 	advances := e.lines[carLine].Text.Advances
 	for i := carCol - 1; i >= 0; i-- {
-		_, s := e.rr.runeBefore(e.rr.caret)
-		e.rr.caret -= s
+		_, s := e.buffer().runeBefore(e.caret)
+		e.caret -= s
 		x -= advances[i]
 	}
 	e.carXOff = -x
@@ -516,8 +677,8 @@ func (e *Editor) moveEnd() {
 	}
 	for i := carCol; i < len(l.Text.Advances)-end; i++ {
 		adv := l.Text.Advances[i]
-		_, s := e.rr.runeAt(e.rr.caret)
-		e.rr.caret += s
+		_, s := e.buffer().runeAt(e.caret)
+		e.caret += s
 		x += adv
 	}
 	a := align(e.Alignment, l.Width, e.viewSize.X)
@@ -548,47 +709,63 @@ func (e *Editor) scrollToCaret() {
 	}
 }
 
-func (e *Editor) hasSelection(k key.Event) bool {
-	return false
+func (e *Editor) hasSelection() bool {
+	return e.selecting && e.anchor != e.caret
+}
+
+// extendSelection starts a selection at the caret when shift is held,
+// otherwise the selection is cleared.
+func (e *Editor) extendSelection(shift bool) {
+	if !shift {
+		e.selecting = false
+	} else if !e.selecting {
+		e.anchor = e.caret
+		e.selecting = true
+	}
 }
 
 func (e *Editor) command(k key.Event) bool {
+	shift := k.Modifiers.Contain(key.ModShift)
 	switch k.Name {
 	case key.NameReturn, key.NameEnter:
 		e.append("\n")
 	case key.NameDeleteBackward:
-		e.deleteRune()
+		if e.hasSelection() {
+			e.deleteSelection()
+		} else if !e.deletePair() {
+			e.deleteRune()
+		}
 	case key.NameDeleteForward:
-		e.deleteRuneForward()
+		if e.hasSelection() {
+			e.deleteSelection()
+		} else {
+			e.deleteRuneForward()
+		}
 	case key.NameUpArrow:
-		line, carCol, carX, _ := e.LayoutCaret()
+		e.extendSelection(shift)
+		line, _, carX, _ := e.LayoutCaret()
 		e.carXOff = e.moveToLine(carX+e.carXOff, line-1)
-		if k.Modifiers.Contain(key.ModShift) && e.selected != nil {
-			e.selected = &image.Point{X: carCol, Y: line}
-		}
 	case key.NameDownArrow:
-		line, carCol, carX, _ := e.LayoutCaret()
+		e.extendSelection(shift)
+		line, _, carX, _ := e.LayoutCaret()
 		e.carXOff = e.moveToLine(carX+e.carXOff, line+1)
-		if k.Modifiers.Contain(key.ModShift) && e.selected != nil {
-			e.selected = &image.Point{X: carCol, Y: line}
-		}
 	case key.NameLeftArrow:
+		e.extendSelection(shift)
 		e.moveLeft()
-		if k.Modifiers.Contain(key.ModShift) && e.selected != nil {
-			e.selected = &image.Point{X: e.carCol, Y: e.carLine}
-		}
 	case key.NameRightArrow:
+		e.extendSelection(shift)
 		e.moveRight()
-		if k.Modifiers.Contain(key.ModShift) && e.selected != nil {
-			e.selected = &image.Point{X: e.carCol, Y: e.carLine}
-		}
 	case key.NamePageUp:
+		e.extendSelection(shift)
 		e.movePages(-1)
 	case key.NamePageDown:
+		e.extendSelection(shift)
 		e.movePages(+1)
 	case key.NameHome:
+		e.extendSelection(shift)
 		e.moveStart()
 	case key.NameEnd:
+		e.extendSelection(shift)
 		e.moveEnd()
 	default:
 		return false
@@ -596,8 +773,10 @@ func (e *Editor) command(k key.Event) bool {
 	return true
 }
 
-func (s ChangeEvent) isEditorEvent()  {}
-func (s CommandEvent) isEditorEvent() {}
-func (s SubmitEvent) isEditorEvent()  {}
-func (s UpEvent) isEditorEvent()      {}
-func (s DownEvent) isEditorEvent()    {}
+func (s ChangeEvent) isEditorEvent()   {}
+func (s CommandEvent) isEditorEvent()  {}
+func (s SubmitEvent) isEditorEvent()   {}
+func (s AbortEvent) isEditorEvent()    {}
+func (s UpEvent) isEditorEvent()       {}
+func (s DownEvent) isEditorEvent()     {}
+func (s CompleteEvent) isEditorEvent() {}
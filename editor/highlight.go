@@ -0,0 +1,139 @@
+package editor
+
+import (
+	"github.com/wrnrlr/foxtrot/parser"
+	"image/color"
+	"sync"
+)
+
+// SyntaxStyle are the colors of the tokens in highlighted text,
+// tokens with a zero color are painted with the color of the text.
+type SyntaxStyle struct {
+	Symbol   color.RGBA
+	Builtin  color.RGBA
+	String   color.RGBA
+	Number   color.RGBA
+	Comment  color.RGBA
+	Pattern  color.RGBA
+	Operator color.RGBA
+}
+
+func (s *SyntaxStyle) color(t parser.Token) color.RGBA {
+	switch t.Kind {
+	case parser.Symbol:
+		if isBuiltin(t.Val) {
+			return s.Builtin
+		}
+		return s.Symbol
+	case parser.String:
+		return s.String
+	case parser.Number:
+		return s.Number
+	case parser.Comment:
+		return s.Comment
+	case parser.Pattern, parser.Slot:
+		return s.Pattern
+	case parser.Operator:
+		return s.Operator
+	}
+	return color.RGBA{}
+}
+
+// builtins are the names of the symbols that are known to the kernel.
+var builtins struct {
+	sync.RWMutex
+	names map[string]bool
+}
+
+// SetBuiltins sets the names of the symbols that are highlighted as builtins.
+func SetBuiltins(names []string) {
+	m := make(map[string]bool, len(names))
+	for _, n := range names {
+		m[n] = true
+	}
+	builtins.Lock()
+	builtins.names = m
+	builtins.Unlock()
+}
+
+func isBuiltin(name string) bool {
+	builtins.RLock()
+	defer builtins.RUnlock()
+	return builtins.names[name]
+}
+
+// highlightLine are the tokens of a line of text,
+// the offsets of the tokens are relative to the start of the line.
+type highlightLine struct {
+	tokens []parser.Token
+	// The state of the scanner at the start and the end of the line.
+	start, end parser.State
+	valid      bool
+}
+
+// highlighter keeps the tokens of each line of a text, after an edit only the changed lines are scanned again
+// and the lines that follow them when the edit changed the state at the end of a line, like opening a comment.
+type highlighter struct {
+	lines []highlightLine
+}
+
+// edit replaces the removed lines that start at line first by added lines that need to be scanned.
+func (h *highlighter) edit(first, removed, added int) {
+	if first+removed > len(h.lines) {
+		h.lines = nil
+		return
+	}
+	lines := make([]highlightLine, added)
+	h.lines = append(h.lines[:first], append(lines, h.lines[first+removed:]...)...)
+}
+
+// update scans the lines of b that changed since the last update.
+func (h *highlighter) update(b textBuffer) {
+	if n := b.lineCount(); len(h.lines) != n {
+		h.lines = make([]highlightLine, n)
+	}
+	var st parser.State
+	for i := range h.lines {
+		l := &h.lines[i]
+		if !l.valid || l.start != st {
+			s := parser.NewStateScanner(b.slice(b.lineStart(i), b.lineStart(i+1)), st)
+			*l = highlightLine{tokens: s.ScanAll(), start: st, end: s.State(), valid: true}
+		}
+		st = l.end
+	}
+}
+
+// span is a run of text with the same color.
+type span struct {
+	length int
+	color  color.RGBA
+}
+
+// spans returns the colors of the text between the byte positions start and end of b.
+func (h *highlighter) spans(b textBuffer, style *SyntaxStyle, start, end int) []span {
+	var spans []span
+	pos := start
+	for i := b.lineOf(start); i < len(h.lines) && pos < end; i++ {
+		off := b.lineStart(i)
+		for _, t := range h.lines[i].tokens {
+			tstart, tend := off+t.Pos.Offset, off+t.End()
+			if tend <= pos {
+				continue
+			}
+			if tstart >= end {
+				break
+			}
+			if tend > end {
+				tend = end
+			}
+			c := style.color(t)
+			if n := len(spans); n > 0 && spans[n-1].color == c {
+				spans[n-1].length += tend - pos
+			} else {
+				spans = append(spans, span{tend - pos, c})
+			}
+			pos = tend
+		}
+	}
+	return spans
+}
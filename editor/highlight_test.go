@@ -0,0 +1,85 @@
+package editor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wrnrlr/foxtrot/parser"
+	"image/color"
+	"testing"
+)
+
+var testSyntax = &SyntaxStyle{
+	Builtin: color.RGBA{R: 1, A: 255},
+	String:  color.RGBA{G: 1, A: 255},
+	Number:  color.RGBA{B: 1, A: 255},
+	Comment: color.RGBA{R: 2, A: 255},
+	Pattern: color.RGBA{G: 2, A: 255},
+}
+
+// scanned returns the number of lines that are scanned by the next update.
+func scanned(h *highlighter, b textBuffer) int {
+	var st parser.State
+	n := 0
+	for _, l := range h.lines {
+		if !l.valid || l.start != st {
+			n++
+		}
+		st = l.end
+	}
+	if len(h.lines) != b.lineCount() {
+		return b.lineCount()
+	}
+	return n
+}
+
+func TestHighlightSpans(t *testing.T) {
+	SetBuiltins([]string{"Sin"})
+	e := &Editor{}
+	e.SetText("f[x_] := Sin[x] + 2 (* sine *)\n\"a\"")
+	e.highlight.update(e.buffer())
+	assert.Equal(t, []span{
+		{2, color.RGBA{}},
+		{2, testSyntax.Pattern},
+		{5, color.RGBA{}},
+		{3, testSyntax.Builtin},
+		{6, color.RGBA{}},
+		{1, testSyntax.Number},
+		{1, color.RGBA{}},
+		{10, testSyntax.Comment},
+	}, e.highlight.spans(e.buffer(), testSyntax, 0, 30))
+	assert.Equal(t, []span{{1, color.RGBA{}}, {3, testSyntax.String}}, e.highlight.spans(e.buffer(), testSyntax, 30, 35))
+	assert.Equal(t, []span{{1, testSyntax.Builtin}}, e.highlight.spans(e.buffer(), testSyntax, 10, 11))
+}
+
+func TestHighlightIncremental(t *testing.T) {
+	e := &Editor{}
+	e.SetText("a = 1\nb = 2\nc = 3\nd = 4")
+	e.highlight.update(e.buffer())
+	assert.Equal(t, 0, scanned(&e.highlight, e.buffer()))
+
+	e.caret = 7
+	e.append("x")
+	assert.Equal(t, 1, scanned(&e.highlight, e.buffer()))
+	e.highlight.update(e.buffer())
+
+	// Opening a comment changes the lines that follow.
+	e.caret = 0
+	e.append("(*")
+	assert.Equal(t, 1, scanned(&e.highlight, e.buffer()))
+	e.highlight.update(e.buffer())
+	for _, l := range e.highlight.lines {
+		assert.Equal(t, parser.Comment, l.tokens[0].Kind)
+	}
+
+	e.Undo()
+	e.highlight.update(e.buffer())
+	assert.Equal(t, parser.Symbol, e.highlight.lines[3].tokens[0].Kind)
+
+	e.caret = 5
+	e.Paste("\n(* new\nline *)")
+	assert.Equal(t, 3, scanned(&e.highlight, e.buffer()))
+	e.highlight.update(e.buffer())
+
+	want := highlighter{}
+	want.update(e.buffer())
+	assert.Equal(t, want.lines, e.highlight.lines)
+}
@@ -0,0 +1,123 @@
+package editor
+
+import "strings"
+
+// edit is a single step in the undo history, at byte position pos
+// the text deleted was replaced by the text inserted.
+type edit struct {
+	pos               int
+	deleted, inserted string
+	// caret is the caret position before the edit.
+	caret int
+	// typing is set for edits made by typing or by deleting single runes,
+	// consecutive typing edits are undone together.
+	typing bool
+}
+
+// after returns the caret position after the edit.
+func (ed edit) after() int {
+	if ed.inserted == "" && ed.pos < ed.caret {
+		return ed.pos
+	}
+	return ed.pos + len(ed.inserted)
+}
+
+// history is the undo and redo stack of an editor.
+type history struct {
+	undo, redo []edit
+}
+
+// record adds an edit to the history and clears what can be redone.
+func (h *history) record(ed edit) {
+	if ed.deleted == "" && ed.inserted == "" {
+		return
+	}
+	h.redo = nil
+	if n := len(h.undo); n > 0 && h.undo[n-1].merge(ed) {
+		return
+	}
+	h.undo = append(h.undo, ed)
+}
+
+// merge extends ed with next when next continues the same run of typing.
+func (ed *edit) merge(next edit) bool {
+	if !ed.typing || !next.typing || next.caret != ed.after() {
+		return false
+	}
+	switch {
+	case ed.deleted == "" && next.deleted == "":
+		// Typing, a new line starts a new step.
+		if strings.Contains(next.inserted, "\n") || strings.HasSuffix(ed.inserted, "\n") {
+			return false
+		}
+		ed.inserted += next.inserted
+	case ed.inserted == "" && next.inserted == "" && next.pos+len(next.deleted) == ed.pos:
+		// Backspace.
+		ed.pos = next.pos
+		ed.deleted = next.deleted + ed.deleted
+	case ed.inserted == "" && next.inserted == "" && next.pos == ed.pos && ed.caret == ed.pos:
+		// Delete forward.
+		ed.deleted += next.deleted
+	default:
+		return false
+	}
+	return true
+}
+
+func (h *history) canUndo() bool {
+	return len(h.undo) > 0
+}
+
+func (h *history) canRedo() bool {
+	return len(h.redo) > 0
+}
+
+// Undo reverts the last edit, it returns false when there is nothing to undo.
+func (e *Editor) Undo() bool {
+	n := len(e.history.undo)
+	if n == 0 {
+		return false
+	}
+	ed := e.history.undo[n-1]
+	e.history.undo = e.history.undo[:n-1]
+	e.history.redo = append(e.history.redo, ed)
+	e.replace(ed.pos, len(ed.inserted), ed.deleted)
+	e.caret = ed.caret
+	e.afterHistory()
+	return true
+}
+
+// Redo applies the last edit that was undone, it returns false when there is nothing to redo.
+func (e *Editor) Redo() bool {
+	n := len(e.history.redo)
+	if n == 0 {
+		return false
+	}
+	ed := e.history.redo[n-1]
+	e.history.redo = e.history.redo[:n-1]
+	e.history.undo = append(e.history.undo, ed)
+	e.replace(ed.pos, len(ed.deleted), ed.inserted)
+	e.caret = ed.after()
+	e.afterHistory()
+	return true
+}
+
+// CanUndo reports whether there are edits to undo.
+func (e *Editor) CanUndo() bool {
+	return e.history.canUndo()
+}
+
+// CanRedo reports whether there are undone edits to redo.
+func (e *Editor) CanRedo() bool {
+	return e.history.canRedo()
+}
+
+func (e *Editor) afterHistory() {
+	// An undone step is never extended by the next edit.
+	if n := len(e.history.undo); n > 0 {
+		e.history.undo[n-1].typing = false
+	}
+	e.selecting = false
+	e.carXOff = 0
+	e.invalidate()
+}
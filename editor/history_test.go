@@ -0,0 +1,79 @@
+package editor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUndoTyping(t *testing.T) {
+	e := &Editor{}
+	e.SetText("x")
+	e.caret = 1
+	assert.False(t, e.CanUndo())
+	for _, s := range []string{" ", "+", " ", "y"} {
+		e.append(s)
+	}
+	e.append("\n")
+	e.append("z")
+	assert.Equal(t, "x + y\nz", e.Text())
+	assert.True(t, e.Undo())
+	assert.Equal(t, "x + y\n", e.Text())
+	assert.True(t, e.Undo())
+	assert.Equal(t, "x + y", e.Text())
+	assert.True(t, e.Undo())
+	assert.Equal(t, "x", e.Text())
+	assert.Equal(t, 1, e.caret)
+	assert.False(t, e.Undo())
+	assert.True(t, e.Redo())
+	assert.Equal(t, "x + y", e.Text())
+	assert.Equal(t, 5, e.caret)
+	assert.True(t, e.CanRedo())
+}
+
+func TestUndoDelete(t *testing.T) {
+	e := &Editor{}
+	e.SetText("Range[10]")
+	e.caret = 8
+	e.deleteRune()
+	e.deleteRune()
+	assert.Equal(t, "Range[]", e.Text())
+	e.deleteRuneForward()
+	assert.Equal(t, "Range[", e.Text())
+	assert.True(t, e.Undo())
+	assert.Equal(t, "Range[]", e.Text())
+	assert.Equal(t, 6, e.caret)
+	assert.True(t, e.Undo())
+	assert.Equal(t, "Range[10]", e.Text())
+	assert.Equal(t, 8, e.caret)
+	assert.False(t, e.CanUndo())
+}
+
+func TestUndoDeleteSelection(t *testing.T) {
+	e := &Editor{}
+	e.SetText("Plot[x]")
+	e.Select(4, 7)
+	e.deleteSelection()
+	assert.Equal(t, "Plot", e.Text())
+	e.append("s")
+	assert.True(t, e.Undo())
+	assert.True(t, e.Undo())
+	assert.Equal(t, "Plot[x]", e.Text())
+	assert.Equal(t, 7, e.caret)
+}
+
+func TestRedoCleared(t *testing.T) {
+	e := &Editor{}
+	e.SetText("")
+	e.append("a")
+	e.caret = 0
+	e.append("b")
+	assert.Equal(t, "ba", e.Text())
+	assert.True(t, e.Undo())
+	assert.Equal(t, "a", e.Text())
+	e.append("c")
+	assert.False(t, e.CanRedo())
+	assert.Equal(t, "ca", e.Text())
+	assert.True(t, e.Undo())
+	assert.True(t, e.Undo())
+	assert.Equal(t, "", e.Text())
+}
@@ -72,14 +72,28 @@ func (e *Editor) layout(gtx *layout.Context, sh *text.Shaper) {
 		Width:     e.viewSize.X,
 		Offset:    off,
 	}
+	if e.syntax != nil {
+		e.highlight.update(e.buffer())
+	}
 	e.shapes = e.shapes[:0]
 	for {
 		str, off, ok := it.Next()
 		if !ok {
 			break
 		}
-		path := sh.Shape(gtx, e.font, str)
-		e.shapes = append(e.shapes, line{off, path})
+		if e.syntax == nil {
+			path := sh.Shape(gtx, e.font, str)
+			e.shapes = append(e.shapes, line{offset: off, clip: path})
+			continue
+		}
+		for _, sp := range e.highlight.spans(e.buffer(), e.syntax, it.start, it.start+len(str.String)) {
+			var head text.String
+			var adv fixed.Int26_6
+			head, str, adv = splitString(str, sp.length)
+			path := sh.Shape(gtx, e.font, head)
+			e.shapes = append(e.shapes, line{offset: off, clip: path, color: sp.color})
+			off.X += float32(adv) / 64
+		}
 	}
 
 	key.InputOp{Key: &e.eventKey, Focus: e.requestFocus}.Add(gtx.Ops)
@@ -106,6 +120,9 @@ func (e *Editor) layout(gtx *layout.Context, sh *text.Shaper) {
 		}
 		e.caretOn = e.focused && (!blinking || dt%timePerBlink < timePerBlink/2)
 	}
+	if at := e.completion.at; !at.IsZero() {
+		op.InvalidateOp{At: at}.Add(gtx.Ops)
+	}
 
 	gtx.Dimensions = layout.Dimensions{Size: e.viewSize, Baseline: e.dims.Baseline}
 }
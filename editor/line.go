@@ -6,12 +6,15 @@ import (
 	"gioui.org/text"
 	"golang.org/x/image/math/fixed"
 	"image"
+	"image/color"
 	"unicode/utf8"
 )
 
 type line struct {
 	offset f32.Point
 	clip   op.CallOp
+	// color of the text, a zero color is painted with the color of the text style.
+	color color.RGBA
 }
 
 type lineIterator struct {
@@ -22,6 +25,9 @@ type lineIterator struct {
 	Offset    image.Point
 
 	y, prevDesc fixed.Int26_6
+	// pos is the byte position of the next line in the text,
+	// start is the byte position of the text returned by Next.
+	pos, start int
 }
 
 const inf = 1e6
@@ -30,6 +36,8 @@ func (l *lineIterator) Next() (text.String, f32.Point, bool) {
 	for len(l.Lines) > 0 {
 		line := l.Lines[0]
 		l.Lines = l.Lines[1:]
+		l.start = l.pos
+		l.pos += len(line.Text.String)
 		x := align(l.Alignment, line.Width, l.Width) + fixed.I(l.Offset.X)
 		l.y += l.prevDesc + line.Ascent
 		l.prevDesc = line.Descent
@@ -51,6 +59,7 @@ func (l *lineIterator) Next() (text.String, f32.Point, bool) {
 			}
 			off.X += adv
 			_, s := utf8.DecodeRuneInString(str.String)
+			l.start += s
 			str.String = str.String[s:]
 			str.Advances = str.Advances[1:]
 		}
@@ -71,3 +80,17 @@ func (l *lineIterator) Next() (text.String, f32.Point, bool) {
 	}
 	return text.String{}, f32.Point{}, false
 }
+
+// splitString splits str after n bytes, the advance of the first part is returned as well.
+func splitString(str text.String, n int) (text.String, text.String, fixed.Int26_6) {
+	var adv fixed.Int26_6
+	i, off := 0, 0
+	for ; off < n && i < len(str.Advances); i++ {
+		_, s := utf8.DecodeRuneInString(str.String[off:])
+		off += s
+		adv += str.Advances[i]
+	}
+	head := text.String{String: str.String[:off], Advances: str.Advances[:i]}
+	tail := text.String{String: str.String[off:], Advances: str.Advances[i:]}
+	return head, tail, adv
+}
@@ -1,21 +1,321 @@
 package editor
 
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
 // https://www.geeksforgeeks.org/gap-buffer-data-structure/
 // https://darrenburns.net/posts/piece-table/
 // https://www.cs.unm.edu/~crowley/papers/sds.pdf
 // https://web.archive.org/web/20160308183811/http://1017.songtrellisopml.com/whatsbeenwroughtusingpiecetables
 
-type PieceTable struct {
+// runeBlock is the number of bytes between the rune counts in the index of a source.
+const runeBlock = 1024
+
+// source is an append-only byte buffer with an index of its newlines and runes.
+// Because it is never modified, the index only grows when text is appended.
+type source struct {
+	data []byte
+	// newlines are the offsets of the '\n' bytes in data.
+	newlines []int
+	// runes[i] is the number of runes in data[:i*runeBlock].
+	runes []int
 }
 
-func (pt PieceTable) Empty() {}
+// append adds s to the end of the source and returns the offset where it starts.
+func (s *source) append(text string) int {
+	start := len(s.data)
+	s.data = append(s.data, text...)
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			s.newlines = append(s.newlines, start+i)
+		}
+	}
+	if len(s.runes) == 0 {
+		s.runes = append(s.runes, 0)
+	}
+	for n := len(s.runes); n*runeBlock <= len(s.data); n++ {
+		s.runes = append(s.runes, s.runes[n-1]+countRunes(s.data[(n-1)*runeBlock:n*runeBlock]))
+	}
+	return start
+}
 
-func (pt PieceTable) Insert() {}
+// newlinesIn returns the number of newlines in data[start:end].
+func (s *source) newlinesIn(start, end int) int {
+	return sort.SearchInts(s.newlines, end) - sort.SearchInts(s.newlines, start)
+}
 
-func (pt PieceTable) Delete() {}
+// runesBefore returns the number of runes in data[:off].
+func (s *source) runesBefore(off int) int {
+	i := off / runeBlock
+	return s.runes[i] + countRunes(s.data[i*runeBlock:off])
+}
 
-func (pt PieceTable) ItemAt() {}
+// countRunes counts the bytes that start a rune, unlike utf8.RuneCount the counts of
+// two halves of a split rune add up to the count of the whole.
+func countRunes(b []byte) int {
+	n := 0
+	for _, c := range b {
+		if c&0xC0 != 0x80 {
+			n++
+		}
+	}
+	return n
+}
 
-type Piece struct {
+// A piece is a span of text in one of the sources of a PieceTable.
+type piece struct {
+	src           *source
 	start, length int
+	// The number of newlines and runes in the piece.
+	newlines, runes int
+}
+
+func newPiece(src *source, start, length int) piece {
+	return piece{
+		src:      src,
+		start:    start,
+		length:   length,
+		newlines: src.newlinesIn(start, start+length),
+		runes:    src.runesBefore(start+length) - src.runesBefore(start),
+	}
+}
+
+func (p piece) bytes() []byte {
+	return p.src.data[p.start : p.start+p.length]
+}
+
+// PieceTable stores text as a list of pieces of the original text and of the text that was added.
+// Edits never copy existing text, so the cost of an edit depends on the number of pieces and not
+// on the size of the text. Inserting text right after the previous insertion extends its piece,
+// which keeps the number of pieces small while typing.
+// The pieces point into the table, so a PieceTable must not be copied.
+type PieceTable struct {
+	original, added source
+	pieces          []piece
+	size            int
+
+	// changed tracks whether the content
+	// has changed since the last call to Changed.
+	changed bool
+}
+
+// NewPieceTable returns a piece table with s as its original text.
+func NewPieceTable(s string) *PieceTable {
+	t := &PieceTable{}
+	if s != "" {
+		t.original.append(s)
+		t.pieces = []piece{newPiece(&t.original, 0, len(s))}
+		t.size = len(s)
+	}
+	return t
+}
+
+func newPieceTable(s string) textBuffer {
+	return NewPieceTable(s)
+}
+
+func (t *PieceTable) Changed() bool {
+	c := t.changed
+	t.changed = false
+	return c
+}
+
+// Len returns the length of the text in bytes.
+func (t *PieceTable) Len() int {
+	return t.size
+}
+
+func (t *PieceTable) len() int {
+	return t.size
+}
+
+func (t *PieceTable) String() string {
+	var b strings.Builder
+	b.Grow(t.size)
+	for _, p := range t.pieces {
+		b.Write(p.bytes())
+	}
+	return b.String()
+}
+
+func (t *PieceTable) slice(start, end int) string {
+	var b strings.Builder
+	b.Grow(end - start)
+	i, off := t.find(start)
+	for ; i < len(t.pieces) && start < end; i++ {
+		bs := t.pieces[i].bytes()[off:]
+		if len(bs) > end-start {
+			bs = bs[:end-start]
+		}
+		b.Write(bs)
+		start += len(bs)
+		off = 0
+	}
+	return b.String()
+}
+
+// Insert adds s at the byte position pos.
+func (t *PieceTable) Insert(pos int, s string) {
+	t.replace(pos, 0, s)
+}
+
+// Delete removes n bytes at the byte position pos.
+func (t *PieceTable) Delete(pos, n int) {
+	t.replace(pos, n, "")
+}
+
+func (t *PieceTable) replace(pos, n int, s string) {
+	if n > 0 {
+		i := t.split(pos)
+		j := t.split(pos + n)
+		t.pieces = append(t.pieces[:i], t.pieces[j:]...)
+		t.size -= n
+		t.changed = true
+	}
+	if s == "" {
+		return
+	}
+	i := t.split(pos)
+	if i > 0 {
+		if p := &t.pieces[i-1]; p.src == &t.added && p.start+p.length == len(t.added.data) {
+			t.added.append(s)
+			*p = newPiece(p.src, p.start, p.length+len(s))
+			t.size += len(s)
+			t.changed = true
+			return
+		}
+	}
+	start := t.added.append(s)
+	t.pieces = append(t.pieces, piece{})
+	copy(t.pieces[i+1:], t.pieces[i:])
+	t.pieces[i] = newPiece(&t.added, start, len(s))
+	t.size += len(s)
+	t.changed = true
+}
+
+// find returns the index of the piece that contains the byte position pos and the offset of pos in that piece.
+// The end of the text is at offset 0 of the index after the last piece.
+func (t *PieceTable) find(pos int) (int, int) {
+	for i, p := range t.pieces {
+		if pos < p.length {
+			return i, pos
+		}
+		pos -= p.length
+	}
+	return len(t.pieces), 0
+}
+
+// split makes sure a piece starts at pos and returns its index.
+func (t *PieceTable) split(pos int) int {
+	i, off := t.find(pos)
+	if off == 0 {
+		return i
+	}
+	p := t.pieces[i]
+	t.pieces = append(t.pieces, piece{})
+	copy(t.pieces[i+1:], t.pieces[i:])
+	t.pieces[i] = newPiece(p.src, p.start, off)
+	t.pieces[i+1] = newPiece(p.src, p.start+off, p.length-off)
+	return i + 1
+}
+
+func (t *PieceTable) runeAt(pos int) (rune, int) {
+	i, off := t.find(pos)
+	if i == len(t.pieces) {
+		return utf8.RuneError, 0
+	}
+	return utf8.DecodeRune(t.pieces[i].bytes()[off:])
+}
+
+func (t *PieceTable) runeBefore(pos int) (rune, int) {
+	if pos <= 0 {
+		return utf8.RuneError, 0
+	}
+	i, off := t.find(pos - 1)
+	return utf8.DecodeLastRune(t.pieces[i].bytes()[:off+1])
+}
+
+func (t *PieceTable) lineCount() int {
+	n := 1
+	for _, p := range t.pieces {
+		n += p.newlines
+	}
+	return n
+}
+
+func (t *PieceTable) lineStart(line int) int {
+	if line <= 0 {
+		return 0
+	}
+	pos := 0
+	for _, p := range t.pieces {
+		if line <= p.newlines {
+			nl := p.src.newlines[sort.SearchInts(p.src.newlines, p.start)+line-1]
+			return pos + nl - p.start + 1
+		}
+		line -= p.newlines
+		pos += p.length
+	}
+	return t.size
+}
+
+func (t *PieceTable) lineOf(pos int) int {
+	line := 0
+	for _, p := range t.pieces {
+		if pos < p.length {
+			return line + p.src.newlinesIn(p.start, p.start+pos)
+		}
+		line += p.newlines
+		pos -= p.length
+	}
+	return line
+}
+
+func (t *PieceTable) runeOffset(pos int) int {
+	n := 0
+	for _, p := range t.pieces {
+		if pos < p.length {
+			return n + p.src.runesBefore(p.start+pos) - p.src.runesBefore(p.start)
+		}
+		n += p.runes
+		pos -= p.length
+	}
+	return n
+}
+
+func (t *PieceTable) byteOffset(runes int) int {
+	pos := 0
+	for _, p := range t.pieces {
+		if runes < p.runes {
+			return pos + p.offsetOfRune(runes)
+		}
+		runes -= p.runes
+		pos += p.length
+	}
+	return t.size
+}
+
+// offsetOfRune returns the byte offset in the piece where the rune with index r starts.
+func (p piece) offsetOfRune(r int) int {
+	src := p.src
+	want := src.runesBefore(p.start) + r
+	// The last block that starts with fewer runes before it than wanted.
+	b := sort.Search(len(src.runes), func(i int) bool { return src.runes[i] > want }) - 1
+	off := b * runeBlock
+	if off < p.start {
+		off = p.start
+	}
+	n := src.runesBefore(off)
+	for ; off < p.start+p.length; off++ {
+		if src.data[off]&0xC0 != 0x80 {
+			if n == want {
+				break
+			}
+			n++
+		}
+	}
+	return off - p.start
 }
@@ -1,12 +1,15 @@
 package editor
 
 import (
+	"gioui.org/f32"
 	"gioui.org/layout"
 	"gioui.org/op"
 	"gioui.org/op/paint"
 	"gioui.org/text"
+	"gioui.org/unit"
 	"gioui.org/widget"
 	"image/color"
+	"time"
 )
 
 type EditorStyle struct {
@@ -18,6 +21,15 @@ type EditorStyle struct {
 	// HintColor is the color of hint text.
 	HintColor  color.RGBA
 	CaretColor color.RGBA
+	// SelectionColor is the background color of selected text.
+	SelectionColor color.RGBA
+	// BracketColor is the background color of the bracket at the caret and its matching bracket.
+	BracketColor color.RGBA
+	// Syntax are the colors of highlighted code, text is not highlighted when it is nil.
+	Syntax *SyntaxStyle
+	// CompletionDelay is the time after typing a symbol until its completions are requested,
+	// when it is zero completions are only requested with a key binding.
+	CompletionDelay time.Duration
 
 	Shaper *text.Shaper
 }
@@ -37,7 +49,13 @@ func (e EditorStyle) Layout(gtx *layout.Context, editor *Editor) {
 	if h := gtx.Dimensions.Size.Y; gtx.Constraints.Height.Min < h {
 		gtx.Constraints.Height.Min = h
 	}
+	editor.syntax = e.Syntax
+	editor.completion.delay = e.CompletionDelay
 	editor.Layout(gtx, e.Shaper, e.Font)
+	paint.ColorOp{Color: e.SelectionColor}.Add(gtx.Ops)
+	editor.PaintSelection(gtx)
+	paint.ColorOp{Color: e.BracketColor}.Add(gtx.Ops)
+	editor.PaintBrackets(gtx)
 	if editor.Len() > 0 {
 		paint.ColorOp{Color: e.Color}.Add(gtx.Ops)
 		editor.PaintText(gtx)
@@ -47,4 +65,44 @@ func (e EditorStyle) Layout(gtx *layout.Context, editor *Editor) {
 	paint.ColorOp{Color: e.Color}.Add(gtx.Ops)
 	editor.PaintCaret(gtx)
 	stack.Pop()
+	if editor.Completing() {
+		e.layoutCompletions(gtx, editor)
+	}
+}
+
+// layoutCompletions lists the completions below the text with the name and usage of each symbol.
+func (e EditorStyle) layoutCompletions(gtx *layout.Context, editor *Editor) {
+	dims, cs := gtx.Dimensions, gtx.Constraints
+	gtx.Constraints.Height.Min = 0
+	gap := gtx.Px(unit.Sp(16))
+	items, selected := editor.visibleCompletions()
+	y := float32(dims.Size.Y)
+	for i, c := range items {
+		var stack op.StackOp
+		stack.Push(gtx.Ops)
+		op.TransformOp{}.Offset(f32.Point{Y: y}).Add(gtx.Ops)
+		var macro op.MacroOp
+		macro.Record(gtx.Ops)
+		paint.ColorOp{Color: e.Color}.Add(gtx.Ops)
+		gtx.Constraints.Width = layout.Constraint{Max: cs.Width.Max}
+		Label{MaxLines: 1}.Layout(gtx, e.Shaper, e.Font, c.Name)
+		w, h := gtx.Dimensions.Size.X+gap, gtx.Dimensions.Size.Y
+		if w < cs.Width.Max {
+			op.TransformOp{}.Offset(f32.Point{X: float32(w)}).Add(gtx.Ops)
+			paint.ColorOp{Color: e.HintColor}.Add(gtx.Ops)
+			gtx.Constraints.Width.Max = cs.Width.Max - w
+			Label{MaxLines: 1}.Layout(gtx, e.Shaper, e.Font, c.Usage)
+		}
+		macro.Stop()
+		if i == selected {
+			paint.ColorOp{Color: e.SelectionColor}.Add(gtx.Ops)
+			paint.PaintOp{Rect: f32.Rectangle{Max: f32.Point{X: float32(dims.Size.X), Y: float32(h)}}}.Add(gtx.Ops)
+		}
+		macro.Add()
+		stack.Pop()
+		y += float32(h)
+	}
+	gtx.Constraints = cs
+	dims.Size.Y = int(y)
+	gtx.Dimensions = dims
 }
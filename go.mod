@@ -6,6 +6,7 @@ require (
 	gioui.org v0.0.0-20200116122050-18cddc030077
 	github.com/RoaringBitmap/roaring v0.4.21 // indirect
 	github.com/alecthomas/participle v0.4.1
+	github.com/atotto/clipboard v0.1.4
 	github.com/blevesearch/bleve v0.8.1
 	github.com/blevesearch/blevex v0.0.0-20190916190636-152f0fe5c040 // indirect
 	github.com/blevesearch/go-porterstemmer v1.0.2 // indirect
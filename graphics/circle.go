@@ -1,13 +1,16 @@
 package graphics
 
 import (
+	"fmt"
 	"gioui.org/f32"
 	"gioui.org/layout"
 	"gioui.org/op"
 	"gioui.org/unit"
 	"github.com/corywalker/expreduce/expreduce/atoms"
+	"github.com/wrnrlr/foxtrot/raster"
 	"github.com/wrnrlr/shape"
 	"image"
+	"io"
 )
 
 func toCircle(e *atoms.Expression) (*Circle, error) {
@@ -45,12 +48,20 @@ func (c Circle) Draw(ctx *context, gtx *layout.Context) {
 	rgba := *ctx.style.StrokeColor
 	var stack op.StackOp
 	stack.Push(gtx.Ops)
-	r := shape.Circle{center, radius}.Stroke(rgba, ctx.style.Thickness, gtx)
+	r := shape.Circle{center, radius}.Stroke(rgba, ctx.strokeWidth(), gtx)
 	stack.Pop()
 	p := image.Point{X: int(r.Max.X), Y: int(r.Max.Y)}
 	gtx.Dimensions = layout.Dimensions{Size: p, Baseline: int(r.Max.Y / 2)}
 }
 
+func (c Circle) SVG(ctx *context, w io.Writer) {
+	fmt.Fprintf(w, "<circle cx=\"%s\" cy=\"%s\" r=\"%s\" %s/>\n", svgNum(c.center.X), svgNum(c.center.Y), svgNum(c.radius), ctx.svgStroke())
+}
+
+func (c Circle) Rasterize(ctx *context, cv *raster.Canvas) {
+	cv.StrokeCircle(ctx.pixel(c.center), c.radius*ctx.scale, ctx.strokeWidth())
+}
+
 func (c Circle) BoundingBox() (bbox f32.Rectangle) {
 	min := f32.Point{X: c.center.X - c.radius, Y: c.center.Y - c.radius}
 	max := f32.Point{X: c.center.X + c.radius, Y: c.center.Y + c.radius}
@@ -5,7 +5,9 @@ import (
 	"gioui.org/f32"
 	"gioui.org/layout"
 	"github.com/corywalker/expreduce/expreduce/atoms"
+	"github.com/wrnrlr/foxtrot/raster"
 	"image/color"
+	"io"
 )
 
 type RGBColor struct {
@@ -17,6 +19,14 @@ func (c RGBColor) Draw(ctx *context, gtx *layout.Context) {
 	*ctx.style.StrokeColor = c.color
 }
 
+func (c RGBColor) SVG(ctx *context, w io.Writer) {
+	*ctx.style.StrokeColor = c.color
+}
+
+func (c RGBColor) Rasterize(ctx *context, cv *raster.Canvas) {
+	*ctx.style.StrokeColor = c.color
+}
+
 func (c RGBColor) BoundingBox() (bbox f32.Rectangle) {
 	return bbox
 }
@@ -14,6 +14,8 @@ type context struct {
 	//
 	BBox  f32.Rectangle
 	style *Style
+	// scale is the number of pixels per unit and lineWidth the default stroke width in pixels.
+	scale, lineWidth float32
 }
 
 func (c context) width() float32 {
@@ -43,3 +45,24 @@ func (c context) y(y float32) float32 {
 func (c context) transformPoint(p f32.Point) f32.Point {
 	return f32.Point{X: c.x(p.X), Y: c.y(p.Y)}
 }
+
+// pixel converts p to the pixel coordinates of a raster.Canvas, where the y axis points down.
+func (c context) pixel(p f32.Point) f32.Point {
+	return f32.Point{X: (p.X - c.BBox.Min.X) * c.scale, Y: (c.BBox.Max.Y - p.Y) * c.scale}
+}
+
+func (c context) pixels(points []f32.Point) []f32.Point {
+	ps := make([]f32.Point, len(points))
+	for i, p := range points {
+		ps[i] = c.pixel(p)
+	}
+	return ps
+}
+
+// strokeWidth is the width of lines in pixels, a Thickness is a fraction of the width of the graphics.
+func (c context) strokeWidth() float32 {
+	if c.style.Thickness <= 0 {
+		return c.lineWidth
+	}
+	return c.style.Thickness * c.width() * c.scale
+}
@@ -39,3 +39,13 @@ func TestSize(t *testing.T) {
 	assert.Equal(t, float32(2), ctx.width())
 	assert.Equal(t, float32(2), ctx.height())
 }
+
+func TestStrokeWidth(t *testing.T) {
+	bbox := f32.Rectangle{Min: f32.Point{X: 0, Y: 0}, Max: f32.Point{X: 2, Y: 2}}
+	ctx := &context{BBox: bbox, style: &Style{}, scale: 100, lineWidth: 1}
+	assert.Equal(t, float32(1), ctx.strokeWidth())
+	Thickness{thickness: 0.01}.Draw(ctx, nil)
+	assert.Equal(t, float32(2), ctx.strokeWidth())
+	Thickness{thickness: 0.02}.Rasterize(ctx, nil)
+	assert.Equal(t, float32(4), ctx.strokeWidth())
+}
@@ -1,15 +1,61 @@
 package graphics
 
+import (
+	"errors"
+	"gioui.org/f32"
+	"gioui.org/layout"
+	"github.com/corywalker/expreduce/expreduce/atoms"
+	"github.com/wrnrlr/foxtrot/raster"
+	"io"
+)
+
 type Directive interface {
 	Set(style *Style)
 }
 
+// Thickness sets the width of the lines that follow as a fraction of the width of the graphics.
 type Thickness struct {
 	thickness float32
 }
 
+func toThickness(e *atoms.Expression) (*Thickness, error) {
+	if e.Len() != 1 {
+		return nil, errors.New("Thickness[] should have 1 float argument")
+	}
+	t, err := toFloat(e.GetPart(1))
+	if err != nil {
+		return nil, err
+	}
+	return &Thickness{thickness: t}, nil
+}
+
 func (t Thickness) Set(style *Style) {
 	style.Thickness = t.thickness
 }
 
+func (t Thickness) Draw(ctx *context, gtx *layout.Context) {
+	t.Set(ctx.style)
+}
+
+func (t Thickness) SVG(ctx *context, w io.Writer) {
+	t.Set(ctx.style)
+}
+
+func (t Thickness) Rasterize(ctx *context, c *raster.Canvas) {
+	t.Set(ctx.style)
+}
+
+func (t Thickness) BoundingBox() (bbox f32.Rectangle) {
+	return bbox
+}
+
+func isDirective(p Primitive) bool {
+	switch p.(type) {
+	case *RGBColor, RGBColor, *Thickness, Thickness:
+		return true
+	default:
+		return false
+	}
+}
+
 type CMYKColor struct{}
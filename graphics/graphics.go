@@ -9,10 +9,12 @@ import (
 	"gioui.org/unit"
 	"github.com/corywalker/expreduce/expreduce/atoms"
 	"github.com/corywalker/expreduce/pkg/expreduceapi"
+	"github.com/wrnrlr/foxtrot/raster"
 	"github.com/wrnrlr/foxtrot/style"
 	"github.com/wrnrlr/foxtrot/util"
 	"github.com/wrnrlr/shape"
 	"image"
+	"image/color"
 )
 
 type Box f32.Rectangle
@@ -44,9 +46,18 @@ type Graphics struct {
 
 type primetives []Primitive
 
+// bbox is the union of the bounding boxes of all primitives, directives like RGBColor are ignored.
 func (ps primetives) bbox() (bbox f32.Rectangle) {
+	first := true
 	for _, e := range ps {
+		if isDirective(e) {
+			continue
+		}
 		b := e.BoundingBox()
+		if first {
+			bbox, first = b, false
+			continue
+		}
 		bbox.Min.X = min(b.Min.X, bbox.Min.X)
 		bbox.Min.Y = min(b.Min.Y, bbox.Min.Y)
 		bbox.Max.X = max(b.Max.X, bbox.Max.X)
@@ -84,8 +95,11 @@ func (g *Graphics) Layout(gtx *layout.Context, s style.Style) {
 	dims := g.Dimensions(gtx, s)
 	//g.drawAxis(gtx, s)
 	//g.drawYAxis(gtx, s)
+	// Lines are 1sp wide until a Thickness directive is seen, like when rasterizing.
 	*g.ctx.style.StrokeColor = util.Black
-	g.ctx.style.Thickness = float32(0.95)
+	g.ctx.style.Thickness = 0
+	g.ctx.scale = float32(gtx.Px(unit.Sp(100)))
+	g.ctx.lineWidth = float32(gtx.Px(unit.Sp(1)))
 	var stack op.StackOp
 	for _, p := range g.elements {
 		stack.Push(gtx.Ops)
@@ -95,6 +109,24 @@ func (g *Graphics) Layout(gtx *layout.Context, s style.Style) {
 	gtx.Dimensions = dims
 }
 
+// Rasterize draws g on c at the size of Dimensions, the y axis points up and the stroke color starts out black.
+func (g *Graphics) Rasterize(gtx *layout.Context, s style.Style, c *raster.Canvas) {
+	dims := g.Dimensions(gtx, s)
+	black := color.RGBA{A: 255}
+	ctx := &context{
+		BBox:      g.BBox,
+		style:     &Style{StrokeColor: &black},
+		scale:     float32(gtx.Px(unit.Sp(100))),
+		lineWidth: float32(gtx.Px(unit.Sp(1)))}
+	col := c.Color
+	for _, p := range g.elements {
+		c.Color = *ctx.style.StrokeColor
+		p.Rasterize(ctx, c)
+	}
+	c.Color = col
+	gtx.Dimensions = dims
+}
+
 func (g Graphics) drawAxis(gtx *layout.Context, s style.Style) {
 	width := float32(gtx.Px(unit.Sp(1)))
 	dims := g.Dimensions(gtx, s)
@@ -120,14 +152,7 @@ func (g *Graphics) size() (bbox f32.Point) {
 }
 
 func (g *Graphics) calculateBoundingBox() (bbox f32.Rectangle) {
-	for _, e := range g.elements {
-		b := e.BoundingBox()
-		bbox.Min.X = min(b.Min.X, bbox.Min.X)
-		bbox.Min.Y = min(b.Min.Y, bbox.Min.Y)
-		bbox.Max.X = max(b.Max.X, bbox.Max.X)
-		bbox.Max.Y = max(b.Max.Y, bbox.Max.Y)
-	}
-	return bbox
+	return g.elements.bbox()
 }
 
 func FromEx(expr *atoms.Expression, st *Style) (*Graphics, error) {
@@ -148,7 +173,7 @@ func FromEx(expr *atoms.Expression, st *Style) (*Graphics, error) {
 		return nil, err
 	}
 	g.elements = primitives
-	g.calculateBoundingBox()
+	g.BBox = g.calculateBoundingBox()
 	return &g, err
 }
 
@@ -186,6 +211,8 @@ func toPrimetive(ex expreduceapi.Ex) (p Primitive, err error) {
 	switch expr.HeadStr() {
 	case "System`RGBColor":
 		p, err = toColor(expr)
+	case "System`Thickness":
+		p, err = toThickness(expr)
 	case "System`Circle":
 		p, err = toCircle(expr)
 	case "System`Rectangle":
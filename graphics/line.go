@@ -1,11 +1,14 @@
 package graphics
 
 import (
+	"fmt"
 	"gioui.org/f32"
 	"gioui.org/layout"
 	"gioui.org/unit"
 	"github.com/corywalker/expreduce/expreduce/atoms"
+	"github.com/wrnrlr/foxtrot/raster"
 	"github.com/wrnrlr/shape"
+	"io"
 )
 
 type Line struct {
@@ -24,12 +27,22 @@ func toLine(e *atoms.Expression) (*Line, error) {
 func (l Line) Draw(ctx *context, gtx *layout.Context) {
 	points := l.transformePoints(l.points, ctx)
 	points = l.scalePoints(points, float32(gtx.Px(unit.Sp(100))))
-	width := float32(gtx.Px(unit.Sp(1)))
 	rgba := *ctx.style.StrokeColor
-	shape.Line(points).Stroke(rgba, width, gtx)
+	shape.Line(points).Stroke(rgba, ctx.strokeWidth(), gtx)
+}
+
+func (l Line) SVG(ctx *context, w io.Writer) {
+	fmt.Fprintf(w, "<polyline points=\"%s\" %s/>\n", svgPoints(l.points), ctx.svgStroke())
+}
+
+func (l Line) Rasterize(ctx *context, c *raster.Canvas) {
+	c.StrokeLine(ctx.pixels(l.points), ctx.strokeWidth())
 }
 
 func (l Line) BoundingBox() (bb f32.Rectangle) {
+	if len(l.points) > 0 {
+		bb = f32.Rectangle{Min: l.points[0], Max: l.points[0]}
+	}
 	for _, p := range l.points {
 		if bb.Min.X > p.X {
 			bb.Min.X = p.X
@@ -3,9 +3,15 @@ package graphics
 import (
 	"gioui.org/f32"
 	"gioui.org/layout"
+	"github.com/wrnrlr/foxtrot/raster"
+	"io"
 )
 
 type Primitive interface {
 	Draw(ctx *context, gtx *layout.Context)
+	// SVG writes the primitive as an SVG element in the coordinates of the Graphics.
+	SVG(ctx *context, w io.Writer)
+	// Rasterize draws the primitive on a software canvas.
+	Rasterize(ctx *context, c *raster.Canvas)
 	BoundingBox() (bbox f32.Rectangle)
 }
@@ -1,10 +1,13 @@
 package graphics
 
 import (
+	"fmt"
 	"gioui.org/f32"
 	"gioui.org/layout"
 	"github.com/corywalker/expreduce/expreduce/atoms"
+	"github.com/wrnrlr/foxtrot/raster"
 	"github.com/wrnrlr/shape"
+	"io"
 )
 
 func toRectangle(e *atoms.Expression) (*Rectangle, error) {
@@ -39,9 +42,21 @@ func (r Rectangle) Draw(ctx *context, gtx *layout.Context) {
 	p1 := r.min.Mul(100)
 	p2 := r.max.Mul(100)
 	rgba := *ctx.style.StrokeColor
-	shape.Rectangle{p1, p2}.Stroke(rgba, ctx.style.Thickness, gtx)
+	shape.Rectangle{p1, p2}.Stroke(rgba, ctx.strokeWidth(), gtx)
+}
+
+func (r Rectangle) SVG(ctx *context, w io.Writer) {
+	bb := r.BoundingBox()
+	fmt.Fprintf(w, "<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" %s/>\n",
+		svgNum(bb.Min.X), svgNum(bb.Min.Y), svgNum(bb.Dx()), svgNum(bb.Dy()), ctx.svgStroke())
+}
+
+func (r Rectangle) Rasterize(ctx *context, c *raster.Canvas) {
+	bb := r.BoundingBox()
+	corners := []f32.Point{bb.Min, {X: bb.Max.X, Y: bb.Min.Y}, bb.Max, {X: bb.Min.X, Y: bb.Max.Y}}
+	c.StrokePolygon(ctx.pixels(corners), ctx.strokeWidth())
 }
 
 func (r Rectangle) BoundingBox() (bbox f32.Rectangle) {
-	return f32.Rectangle{Min: r.min, Max: r.max}
+	return f32.Rectangle{Min: r.min, Max: r.max}.Canon()
 }
@@ -0,0 +1,73 @@
+package graphics
+
+import (
+	"bytes"
+	"fmt"
+	"gioui.org/f32"
+	"image/color"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// svgScale is the number of pixels per graphics unit, the same as in Layout.
+const svgScale = 100
+
+// SVG writes g as a standalone SVG document. The y axis points up like it does in the kernel,
+// the stroke color starts out black and lines are 1px wide until a Thickness directive is seen.
+func (g *Graphics) SVG(w io.Writer) error {
+	bbox := g.elements.bbox()
+	if bbox.Dx() == 0 && bbox.Dy() == 0 {
+		bbox.Max = bbox.Min.Add(f32.Point{X: 1, Y: 1})
+	}
+	black := color.RGBA{A: 255}
+	ctx := &context{BBox: bbox, style: &Style{StrokeColor: &black}}
+	width, height := ctx.width(), ctx.height()
+	pad := max(width, height) * 0.02
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="%s %s %s %s">`,
+		svgNum((width+2*pad)*svgScale), svgNum((height+2*pad)*svgScale),
+		svgNum(bbox.Min.X-pad), svgNum(-bbox.Max.Y-pad), svgNum(width+2*pad), svgNum(height+2*pad))
+	b.WriteString("\n")
+	b.WriteString(`<g transform="scale(1,-1)" fill="none">`)
+	b.WriteString("\n")
+	for _, p := range g.elements {
+		p.SVG(ctx, &b)
+	}
+	b.WriteString("</g>\n</svg>\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// WriteSVGFile writes g to filename as an SVG document.
+func (g *Graphics) WriteSVGFile(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return g.SVG(file)
+}
+
+// svgStroke returns the stroke attributes for the current style of ctx.
+func (c *context) svgStroke() string {
+	col := *c.style.StrokeColor
+	rgb := fmt.Sprintf("#%02x%02x%02x", col.R, col.G, col.B)
+	if c.style.Thickness <= 0 {
+		return fmt.Sprintf(`stroke="%s" stroke-width="1" vector-effect="non-scaling-stroke"`, rgb)
+	}
+	return fmt.Sprintf(`stroke="%s" stroke-width="%s"`, rgb, svgNum(c.style.Thickness*c.width()))
+}
+
+func svgPoints(points []f32.Point) string {
+	ps := make([]string, len(points))
+	for i, p := range points {
+		ps[i] = svgNum(p.X) + "," + svgNum(p.Y)
+	}
+	return strings.Join(ps, " ")
+}
+
+func svgNum(f float32) string {
+	return strconv.FormatFloat(float64(f), 'f', -1, 32)
+}
@@ -0,0 +1,42 @@
+package graphics
+
+import (
+	"bytes"
+	"gioui.org/f32"
+	"github.com/corywalker/expreduce/expreduce/atoms"
+	"github.com/corywalker/expreduce/pkg/expreduceapi"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+)
+
+func point(x, y int64) expreduceapi.Ex {
+	return atoms.E(atoms.S("List"), atoms.NewInt(x), atoms.NewInt(y))
+}
+
+func TestBoundingBox(t *testing.T) {
+	ex := atoms.E(atoms.S("Graphics"), atoms.E(atoms.S("List"),
+		atoms.E(atoms.S("RGBColor"), atoms.NewInt(1), atoms.NewInt(0), atoms.NewInt(0)),
+		atoms.E(atoms.S("Triangle"), point(1, 1), point(3, 1), point(2, 4)),
+		atoms.E(atoms.S("Line"), atoms.E(atoms.S("List"), point(2, 2), point(5, 3)))))
+	g, err := FromEx(ex, &Style{})
+	assert.Nil(t, err)
+	assert.Equal(t, f32.Rectangle{Min: f32.Point{X: 1, Y: 1}, Max: f32.Point{X: 5, Y: 4}}, g.BBox)
+}
+
+func TestSVG(t *testing.T) {
+	ex := atoms.E(atoms.S("Graphics"), atoms.E(atoms.S("List"),
+		atoms.E(atoms.S("Circle"), point(0, 0), atoms.NewInt(1)),
+		atoms.E(atoms.S("RGBColor"), atoms.NewInt(0), atoms.NewInt(0), atoms.NewInt(1)),
+		atoms.E(atoms.S("Thickness"), atoms.NewReal(big.NewFloat(0.5))),
+		atoms.E(atoms.S("Rectangle"), point(0, 0), point(1, 1))))
+	g, err := FromEx(ex, &Style{})
+	assert.Nil(t, err)
+	buffer := new(bytes.Buffer)
+	err = g.SVG(buffer)
+	assert.Nil(t, err)
+	s := buffer.String()
+	assert.Contains(t, s, `viewBox="-1.04 -1.04 2.08 2.08"`)
+	assert.Contains(t, s, `<circle cx="0" cy="0" r="1" stroke="#000000" stroke-width="1" vector-effect="non-scaling-stroke"/>`)
+	assert.Contains(t, s, `<rect x="0" y="0" width="1" height="1" stroke="#0000ff" stroke-width="1"/>`)
+}
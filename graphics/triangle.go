@@ -2,11 +2,14 @@ package graphics
 
 import (
 	"errors"
+	"fmt"
 	"gioui.org/f32"
 	"gioui.org/layout"
 	"gioui.org/unit"
 	"github.com/corywalker/expreduce/expreduce/atoms"
+	"github.com/wrnrlr/foxtrot/raster"
 	"github.com/wrnrlr/shape"
+	"io"
 )
 
 func toTriangle(e *atoms.Expression) (*Triangle, error) {
@@ -38,11 +41,18 @@ func (t Triangle) Draw(ctx *context, gtx *layout.Context) {
 	p2 := t.p2.Mul(float32(gtx.Px(unit.Sp(100))))
 	p3 := t.p3.Mul(float32(gtx.Px(unit.Sp(100))))
 	rgba := *ctx.style.StrokeColor
-	shape.Triangle{p1, p2, p3}.Stroke(rgba, float32(gtx.Px(unit.Sp(5))), gtx)
+	shape.Triangle{p1, p2, p3}.Stroke(rgba, ctx.strokeWidth(), gtx)
 }
 
-func (r Triangle) BoundingBox() (bbox f32.Rectangle) {
-	min := f32.Point{X: 0, Y: 0}
-	max := f32.Point{X: 1, Y: 1}
-	return f32.Rectangle{Min: min, Max: max}
+func (t Triangle) SVG(ctx *context, w io.Writer) {
+	points := []f32.Point{t.p1, t.p2, t.p3}
+	fmt.Fprintf(w, "<polygon points=\"%s\" %s/>\n", svgPoints(points), ctx.svgStroke())
+}
+
+func (t Triangle) Rasterize(ctx *context, c *raster.Canvas) {
+	c.StrokePolygon(ctx.pixels([]f32.Point{t.p1, t.p2, t.p3}), ctx.strokeWidth())
+}
+
+func (t Triangle) BoundingBox() (bbox f32.Rectangle) {
+	return Line{points: []f32.Point{t.p1, t.p2, t.p3}}.BoundingBox()
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/corywalker/expreduce/expreduce/atoms"
 	"github.com/corywalker/expreduce/pkg/expreduceapi"
 	"math"
+	"math/big"
 )
 
 func toFloat(e expreduceapi.Ex) (float32, error) {
@@ -19,6 +20,11 @@ func toFloat(e expreduceapi.Ex) (float32, error) {
 		i, _ := f.Val.Float32()
 		return i, nil
 	}
+	r, isRational := e.(*atoms.Rational)
+	if isRational {
+		f, _ := new(big.Rat).SetFrac(r.Num, r.Den).Float32()
+		return f, nil
+	}
 	return 0, errors.New("Connot be converted to a float")
 }
 
@@ -125,5 +131,5 @@ func min(n, m float32) float32 {
 }
 
 func max(n, m float32) float32 {
-	return float32(math.Min(float64(n), float64(m)))
+	return float32(math.Max(float64(n), float64(m)))
 }
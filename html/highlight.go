@@ -0,0 +1,66 @@
+package html
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Highlight returns src as escaped HTML where strings, numbers, comments,
+// symbols and brackets are wrapped in a span with the class str, num, cmt, sym and brk.
+func Highlight(src string) string {
+	var b strings.Builder
+	rs := []rune(src)
+	for i := 0; i < len(rs); {
+		j := i + 1
+		class := ""
+		switch r := rs[i]; {
+		case r == '"':
+			for j < len(rs) && rs[j] != '"' {
+				if rs[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(rs))
+			class = "str"
+		case r == '(' && i+1 < len(rs) && rs[i+1] == '*':
+			j = i + 2
+			for j+1 < len(rs) && !(rs[j] == '*' && rs[j+1] == ')') {
+				j++
+			}
+			j = min(j+2, len(rs))
+			class = "cmt"
+		case unicode.IsDigit(r):
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.') {
+				j++
+			}
+			class = "num"
+		case unicode.IsLetter(r) || r == '$':
+			for j < len(rs) && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j]) || rs[j] == '$' || rs[j] == '`') {
+				j++
+			}
+			if unicode.IsUpper(r) {
+				class = "sym"
+			}
+		case strings.ContainsRune("[]{}()", r):
+			class = "brk"
+		}
+		text := html.EscapeString(string(rs[i:j]))
+		if class == "" {
+			b.WriteString(text)
+		} else {
+			fmt.Fprintf(&b, "<span class=\"%s\">%s</span>", class, text)
+		}
+		i = j
+	}
+	return b.String()
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
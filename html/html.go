@@ -0,0 +1,104 @@
+package html
+
+import (
+	"fmt"
+	"github.com/wrnrlr/foxtrot/cell"
+	"html"
+	"io"
+	"os"
+	"strings"
+)
+
+const stylesheet = `
+body { max-width: 50em; margin: 2em auto; padding: 0 1em; font-family: sans-serif; line-height: 1.4; color: #000; }
+.cell { display: flex; margin: 0.5em 0; }
+.label { flex: 0 0 6em; color: #888; font-family: monospace; font-size: 0.8em; padding-top: 0.6em; text-align: right; margin-right: 1em; }
+.content { flex: 1; overflow-x: auto; }
+pre { margin: 0; padding: 0.5em; background: #f7f7f7; }
+pre.input { border-left: 3px solid #4a90d9; }
+.output math { font-size: 1.1em; }
+.output svg { max-width: 300px; max-height: 200px; }
+.error { color: #c00; font-family: monospace; }
+.str { color: #1a8a34; }
+.num { color: #1750eb; }
+.cmt { color: #8c8c8c; font-style: italic; }
+.sym { color: #871094; }
+.brk { color: #555; }
+`
+
+// WriteFile exports cells as a self-contained HTML document.
+func WriteFile(filename string, cells cell.Cells) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return Write(file, cells)
+}
+
+// Write cells as a self-contained HTML document, the title is the text of the first header.
+func Write(w io.Writer, cells cell.Cells) error {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(title(cells)))
+	fmt.Fprintf(&b, "<style>%s</style>\n", stylesheet)
+	b.WriteString("</head>\n<body>\n")
+	for _, c := range cells {
+		writeCell(&b, c)
+	}
+	b.WriteString("</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeCell(b *strings.Builder, c cell.Cell) {
+	switch c.Type() {
+	case cell.H1, cell.H2, cell.H3, cell.H4, cell.H5, cell.H6:
+		level := int(c.Type()-cell.H1) + 1
+		fmt.Fprintf(b, "<h%d>%s</h%d>\n", level, html.EscapeString(c.Text()), level)
+	case cell.Paragraph:
+		for _, p := range strings.Split(c.Text(), "\n\n") {
+			fmt.Fprintf(b, "<p>%s</p>\n", html.EscapeString(p))
+		}
+	case cell.Code:
+		fmt.Fprintf(b, "<pre class=\"code\"><code>%s</code></pre>\n", Highlight(c.Text()))
+	case cell.Input:
+		fmt.Fprintf(b, "<div class=\"cell input\"><div class=\"label\">%s</div>", label(c))
+		fmt.Fprintf(b, "<div class=\"content\"><pre class=\"input\"><code>%s</code></pre></div></div>\n", Highlight(c.Text()))
+	case cell.Output:
+		fmt.Fprintf(b, "<div class=\"cell output\"><div class=\"label\">%s</div><div class=\"content\">", label(c))
+		writeOutput(b, c)
+		b.WriteString("</div></div>\n")
+	}
+}
+
+func writeOutput(b *strings.Builder, c cell.Cell) {
+	if err := c.Err(); err != nil {
+		fmt.Fprintf(b, "<div class=\"error\">%s</div>", html.EscapeString(err.Error()))
+	}
+	if ex := c.Out(); ex != nil {
+		if isGraphics(ex) {
+			writeSVG(b, ex)
+		} else {
+			writeMath(b, ex)
+		}
+	} else if c.Err() == nil {
+		fmt.Fprintf(b, "<pre>%s</pre>", html.EscapeString(c.Text()))
+	}
+}
+
+func label(c cell.Cell) string {
+	if c.Prompt() == 0 {
+		return ""
+	}
+	return html.EscapeString(strings.TrimSpace(c.Label()))
+}
+
+func title(cells cell.Cells) string {
+	for _, c := range cells {
+		if c.Type() >= cell.H1 && c.Type() <= cell.H6 && c.Text() != "" {
+			return c.Text()
+		}
+	}
+	return "Foxtrot Notebook"
+}
@@ -0,0 +1,57 @@
+package html
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/kernel"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	h := cell.NewCell(cell.H1, "", nil)
+	h.SetText("Results & Notes")
+	in := cell.NewCell(cell.Input, "In[1]:= ", nil)
+	in.SetText(`Sqrt[x] + "a<b"`)
+	in.SetPrompt(1)
+	out := cell.NewCell(cell.Output, "Out[1]= ", nil)
+	out.SetPrompt(1)
+	ex, err := kernel.NewKernel().Eval("{1/2, x^2, Sqrt[x]}")
+	assert.Nil(t, err)
+	out.SetOut(ex)
+	failed := cell.NewCell(cell.Output, "Out[2]= ", nil)
+	failed.SetPrompt(2)
+	failed.SetErr(errors.New("Syntax::sntxf"))
+	buffer := new(bytes.Buffer)
+	err = Write(buffer, cell.Cells{h, in, out, failed})
+	assert.Nil(t, err)
+	s := buffer.String()
+	assert.Contains(t, s, "<title>Results &amp; Notes</title>")
+	assert.Contains(t, s, "<h1>Results &amp; Notes</h1>")
+	assert.Contains(t, s, "In[1]:=")
+	assert.Contains(t, s, `<span class="str">&#34;a&lt;b&#34;</span>`)
+	assert.Contains(t, s, "<mfrac><mn>1</mn><mn>2</mn></mfrac>")
+	assert.Contains(t, s, "<msup><mi>x</mi><mn>2</mn></msup>")
+	assert.Contains(t, s, "<msqrt><mi>x</mi></msqrt>")
+	assert.Contains(t, s, `<div class="error">Syntax::sntxf</div>`)
+}
+
+func TestWriteGraphics(t *testing.T) {
+	out := cell.NewCell(cell.Output, "Out[1]= ", nil)
+	ex, err := kernel.Parse("Graphics[{RGBColor[1, 0, 0], Circle[{0, 0}, 2], Line[{{0, 0}, {1, 1}}]}]")
+	assert.Nil(t, err)
+	out.SetOut(ex)
+	buffer := new(bytes.Buffer)
+	err = Write(buffer, cell.Cells{out})
+	assert.Nil(t, err)
+	s := buffer.String()
+	assert.Contains(t, s, "<svg")
+	assert.Contains(t, s, `<circle cx="0" cy="0" r="2" stroke="#ff0000"`)
+	assert.Contains(t, s, `<polyline points="0,0 1,1" stroke="#ff0000"`)
+}
+
+func TestHighlight(t *testing.T) {
+	assert.Equal(t, `<span class="sym">Range</span><span class="brk">[</span><span class="num">10</span><span class="brk">]</span>`, Highlight("Range[10]"))
+	assert.Equal(t, `x <span class="cmt">(* note *)</span>`, Highlight("x (* note *)"))
+}
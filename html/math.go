@@ -0,0 +1,126 @@
+package html
+
+import (
+	"fmt"
+	"github.com/corywalker/expreduce/expreduce/atoms"
+	api "github.com/corywalker/expreduce/pkg/expreduceapi"
+	"github.com/wrnrlr/foxtrot/kernel"
+	"html"
+	"math/big"
+	"strings"
+)
+
+var bigOne = big.NewInt(1)
+var bigTwo = big.NewInt(2)
+
+// writeMath writes ex as MathML, fractions, powers, square roots and lists
+// are laid out the same way output.FromEx typesets them.
+func writeMath(b *strings.Builder, ex api.Ex) {
+	b.WriteString("<math display=\"block\">")
+	mathEx(b, ex)
+	b.WriteString("</math>")
+}
+
+func mathEx(b *strings.Builder, ex api.Ex) {
+	switch ex := ex.(type) {
+	case *atoms.String:
+		fmt.Fprintf(b, "<mtext>%s</mtext>", html.EscapeString(ex.Val))
+	case *atoms.Integer, *atoms.Flt:
+		fmt.Fprintf(b, "<mn>%s</mn>", html.EscapeString(kernel.Format(ex, "InputForm")))
+	case *atoms.Rational:
+		b.WriteString("<mfrac>")
+		fmt.Fprintf(b, "<mn>%s</mn>", ex.Num.String())
+		fmt.Fprintf(b, "<mn>%s</mn>", ex.Den.String())
+		b.WriteString("</mfrac>")
+	case *atoms.Symbol:
+		fmt.Fprintf(b, "<mi>%s</mi>", html.EscapeString(shortName(ex.Name)))
+	case *atoms.Expression:
+		mathExpression(b, ex)
+	default:
+		fmt.Fprintf(b, "<mtext>%s</mtext>", html.EscapeString(kernel.Format(ex, "InputForm")))
+	}
+}
+
+func mathExpression(b *strings.Builder, ex *atoms.Expression) {
+	switch ex.HeadStr() {
+	case "System`Plus":
+		mathOperator(b, ex, "+")
+	case "System`Times":
+		mathOperator(b, ex, "*")
+	case "System`Power":
+		if ex.Len() != 2 {
+			mathGeneric(b, ex)
+		} else if isSqrt(ex) {
+			b.WriteString("<msqrt>")
+			mathEx(b, ex.GetPart(1))
+			b.WriteString("</msqrt>")
+		} else {
+			b.WriteString("<msup>")
+			mathOperand(b, ex.GetPart(1))
+			mathEx(b, ex.GetPart(2))
+			b.WriteString("</msup>")
+		}
+	case "System`List":
+		b.WriteString("<mrow><mo>{</mo>")
+		mathParts(b, ex)
+		b.WriteString("<mo>}</mo></mrow>")
+	case "System`Graphics":
+		b.WriteString("<mtext>-Graphics-</mtext>")
+	default:
+		mathGeneric(b, ex)
+	}
+}
+
+func mathOperator(b *strings.Builder, ex *atoms.Expression, op string) {
+	b.WriteString("<mrow>")
+	for i, part := range ex.Parts[1:] {
+		if i > 0 {
+			fmt.Fprintf(b, "<mo>%s</mo>", op)
+		}
+		mathOperand(b, part)
+	}
+	b.WriteString("</mrow>")
+}
+
+// mathOperand wraps operators that bind less strong than Power in parentheses.
+func mathOperand(b *strings.Builder, ex api.Ex) {
+	e, ok := ex.(*atoms.Expression)
+	if ok && (e.HeadStr() == "System`Plus" || e.HeadStr() == "System`Times") {
+		b.WriteString("<mrow><mo>(</mo>")
+		mathEx(b, ex)
+		b.WriteString("<mo>)</mo></mrow>")
+		return
+	}
+	mathEx(b, ex)
+}
+
+func mathGeneric(b *strings.Builder, ex *atoms.Expression) {
+	b.WriteString("<mrow>")
+	mathEx(b, ex.Parts[0])
+	b.WriteString("<mo>[</mo>")
+	mathParts(b, ex)
+	b.WriteString("<mo>]</mo></mrow>")
+}
+
+func mathParts(b *strings.Builder, ex *atoms.Expression) {
+	for i, part := range ex.Parts[1:] {
+		if i > 0 {
+			b.WriteString("<mo>,</mo>")
+		}
+		mathEx(b, part)
+	}
+}
+
+func isSqrt(ex *atoms.Expression) bool {
+	r, ok := ex.GetPart(2).(*atoms.Rational)
+	return ok && r.Num.Cmp(bigOne) == 0 && r.Den.Cmp(bigTwo) == 0
+}
+
+func isGraphics(ex api.Ex) bool {
+	_, ok := atoms.HeadAssertion(ex, "System`Graphics")
+	return ok
+}
+
+func shortName(name string) string {
+	return name[strings.LastIndex(name, "`")+1:]
+}
@@ -0,0 +1,22 @@
+package html
+
+import (
+	"fmt"
+	"github.com/corywalker/expreduce/expreduce/atoms"
+	api "github.com/corywalker/expreduce/pkg/expreduceapi"
+	"github.com/wrnrlr/foxtrot/graphics"
+	"html"
+	"strings"
+)
+
+// writeSVG writes a Graphics[...] expression as inline SVG.
+func writeSVG(b *strings.Builder, ex api.Ex) {
+	e, _ := ex.(*atoms.Expression)
+	g, err := graphics.FromEx(e, &graphics.Style{})
+	if err == nil {
+		err = g.SVG(b)
+	}
+	if err != nil {
+		fmt.Fprintf(b, "<div class=\"error\">%s</div>", html.EscapeString(err.Error()))
+	}
+}
@@ -0,0 +1,121 @@
+package ipynb
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Jupyter nbformat v4, see https://nbformat.readthedocs.io/en/latest/format_description.html
+
+const (
+	nbformat      = 4
+	nbformatMinor = 4
+)
+
+type notebook struct {
+	Metadata      metadata `json:"metadata"`
+	NBFormat      int      `json:"nbformat"`
+	NBFormatMinor int      `json:"nbformat_minor"`
+	Cells         []nbCell `json:"cells"`
+}
+
+type metadata struct {
+	KernelSpec   *kernelSpec   `json:"kernelspec,omitempty"`
+	LanguageInfo *languageInfo `json:"language_info,omitempty"`
+}
+
+type kernelSpec struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Language    string `json:"language"`
+}
+
+type languageInfo struct {
+	Name          string `json:"name"`
+	FileExtension string `json:"file_extension,omitempty"`
+}
+
+var foxtrotKernel = kernelSpec{Name: "foxtrot", DisplayName: "Foxtrot", Language: "wolfram"}
+
+type nbCell struct {
+	CellType       string       `json:"cell_type"`
+	Metadata       cellMetadata `json:"metadata"`
+	Source         multiline    `json:"source"`
+	ExecutionCount *int         `json:"execution_count,omitempty"`
+	Outputs        []output     `json:"outputs,omitempty"`
+}
+
+// MarshalJSON only writes execution_count and outputs for code cells, as required by nbformat.
+func (c nbCell) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{
+		"cell_type": c.CellType,
+		"metadata":  c.Metadata,
+		"source":    c.Source}
+	if c.CellType == "code" {
+		outputs := c.Outputs
+		if outputs == nil {
+			outputs = []output{}
+		}
+		m["execution_count"] = c.ExecutionCount
+		m["outputs"] = outputs
+	}
+	return json.Marshal(m)
+}
+
+// cellMetadata remembers the Foxtrot cell type when it can't be derived from the Jupyter cell.
+type cellMetadata struct {
+	Foxtrot *foxtrotMetadata `json:"foxtrot,omitempty"`
+}
+
+type foxtrotMetadata struct {
+	Type string `json:"type"`
+}
+
+type output struct {
+	OutputType     string               `json:"output_type"`
+	ExecutionCount *int                 `json:"execution_count,omitempty"`
+	Data           map[string]multiline `json:"data,omitempty"`
+	Metadata       *struct{}            `json:"metadata,omitempty"`
+	Name           string               `json:"name,omitempty"`
+	Text           multiline            `json:"text,omitempty"`
+	EName          string               `json:"ename,omitempty"`
+	EValue         string               `json:"evalue,omitempty"`
+	Traceback      []string             `json:"traceback,omitempty"`
+}
+
+// MarshalJSON always writes execution_count for an execute_result, as required by nbformat, it is null when unknown.
+func (o output) MarshalJSON() ([]byte, error) {
+	type plain output
+	if o.OutputType != "execute_result" {
+		return json.Marshal(plain(o))
+	}
+	return json.Marshal(struct {
+		plain
+		ExecutionCount *int `json:"execution_count"`
+	}{plain(o), o.ExecutionCount})
+}
+
+// multiline is text that Jupyter stores either as a single string or as a list of lines.
+type multiline string
+
+func (m *multiline) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*m = multiline(s)
+		return nil
+	}
+	var lines []string
+	if err := json.Unmarshal(b, &lines); err != nil {
+		return err
+	}
+	*m = multiline(strings.Join(lines, ""))
+	return nil
+}
+
+func (m multiline) MarshalJSON() ([]byte, error) {
+	lines := strings.SplitAfter(string(m), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return json.Marshal(lines)
+}
@@ -0,0 +1,131 @@
+package ipynb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/corywalker/expreduce/expreduce/atoms"
+	api "github.com/corywalker/expreduce/pkg/expreduceapi"
+	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/kernel"
+	"github.com/wrnrlr/foxtrot/theme"
+	"io"
+	"os"
+	"strings"
+)
+
+func ReadFile(filename string) (cell.Cells, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// Read a Jupyter notebook, markdown cells become headers or paragraphs and code cells become input cells
+// that are followed by their outputs.
+func Read(r io.Reader) (cell.Cells, error) {
+	var nb notebook
+	if err := json.NewDecoder(r).Decode(&nb); err != nil {
+		return nil, err
+	}
+	if nb.NBFormat != nbformat {
+		return nil, fmt.Errorf("unsupported nbformat %d, only version %d is supported", nb.NBFormat, nbformat)
+	}
+	styles := theme.DefaultStyles()
+	var cells cell.Cells
+	for _, c := range nb.Cells {
+		switch c.CellType {
+		case "markdown":
+			cells = append(cells, readMarkdown(string(c.Source), styles))
+		case "code":
+			cells = append(cells, readCode(c, styles)...)
+		case "raw":
+			c2 := cell.NewCell(cell.Paragraph, "", styles)
+			c2.SetText(string(c.Source))
+			cells = append(cells, c2)
+		}
+	}
+	return cells, nil
+}
+
+var headers = []cell.Type{cell.H1, cell.H2, cell.H3, cell.H4, cell.H5, cell.H6}
+
+func readMarkdown(src string, styles *theme.Styles) cell.Cell {
+	src = strings.TrimRight(src, "\n")
+	if !strings.Contains(src, "\n") {
+		level := len(src) - len(strings.TrimLeft(src, "#"))
+		if level > 0 && level <= len(headers) && strings.HasPrefix(src[level:], " ") {
+			c := cell.NewCell(headers[level-1], "", styles)
+			c.SetText(strings.TrimSpace(src[level:]))
+			return c
+		}
+	}
+	c := cell.NewCell(cell.Paragraph, "", styles)
+	c.SetText(src)
+	return c
+}
+
+func readCode(c nbCell, styles *theme.Styles) cell.Cells {
+	typ := cell.Input
+	if c.Metadata.Foxtrot != nil && cell.ParseType(c.Metadata.Foxtrot.Type) == cell.Code {
+		typ = cell.Code
+	}
+	n := 0
+	if c.ExecutionCount != nil {
+		n = *c.ExecutionCount
+	}
+	in := cell.NewCell(typ, "", styles)
+	in.SetText(string(c.Source))
+	if n > 0 && typ == cell.Input {
+		in.SetPrompt(n)
+		in.SetLabel(kernel.InLabel(n))
+	}
+	cells := cell.Cells{in}
+	for _, o := range c.Outputs {
+		out := readOutput(o, n, styles)
+		if out != nil {
+			cells = append(cells, out)
+		}
+	}
+	return cells
+}
+
+func readOutput(o output, n int, styles *theme.Styles) cell.Cell {
+	if o.ExecutionCount != nil {
+		n = *o.ExecutionCount
+	}
+	label := ""
+	if n > 0 {
+		label = kernel.OutLabel(n)
+	}
+	c := cell.NewCell(cell.Output, label, styles)
+	c.SetPrompt(n)
+	switch o.OutputType {
+	case "execute_result", "display_data":
+		txt, ok := o.Data["text/plain"]
+		if !ok {
+			return nil
+		}
+		c.SetText(string(txt))
+		c.SetOut(parseOutput(string(txt)))
+	case "stream":
+		c.SetText(string(o.Text))
+		c.SetOut(atoms.NewString(string(o.Text)))
+	case "error":
+		c.SetErr(errors.New(strings.TrimSpace(o.EName + ": " + o.EValue)))
+	default:
+		return nil
+	}
+	return c
+}
+
+// parseOutput reads text/plain as an expression, text produced by another kernel is kept as a string.
+func parseOutput(txt string) api.Ex {
+	ex, err := kernel.Parse(txt)
+	if err != nil || ex == nil {
+		return atoms.NewString(txt)
+	}
+	return ex
+}
@@ -0,0 +1,39 @@
+package ipynb
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wrnrlr/foxtrot/cell"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	r := strings.NewReader(`{
+	 "nbformat": 4,
+	 "nbformat_minor": 4,
+	 "metadata": {},
+	 "cells": [
+	  {"cell_type": "markdown", "metadata": {}, "source": ["## Arithmetic"]},
+	  {"cell_type": "markdown", "metadata": {}, "source": "Some text\nover two lines"},
+	  {"cell_type": "code", "metadata": {}, "execution_count": 2, "source": ["1+1"],
+	   "outputs": [{"output_type": "execute_result", "execution_count": 2, "metadata": {}, "data": {"text/plain": ["2"]}}]}
+	 ]
+	}`)
+	cells, err := Read(r)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(cells))
+	assert.Equal(t, cell.H2, cells[0].Type())
+	assert.Equal(t, "Arithmetic", cells[0].Text())
+	assert.Equal(t, cell.Paragraph, cells[1].Type())
+	assert.Equal(t, cell.Input, cells[2].Type())
+	assert.Equal(t, 2, cells[2].Prompt())
+	assert.Equal(t, cell.Output, cells[3].Type())
+	assert.Equal(t, "Out[2]= ", cells[3].Label())
+	assert.NotNil(t, cells[3].Out())
+}
+
+func TestReadUnsupportedVersion(t *testing.T) {
+	r := strings.NewReader(`{"nbformat": 3, "cells": []}`)
+	_, err := Read(r)
+	assert.NotNil(t, err)
+}
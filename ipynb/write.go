@@ -0,0 +1,85 @@
+package ipynb
+
+import (
+	"encoding/json"
+	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/kernel"
+	"io"
+	"os"
+	"strings"
+)
+
+func WriteFile(filename string, cells cell.Cells) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return Write(file, cells)
+}
+
+// Write cells as a Jupyter notebook, output cells are added to the code cell of their input.
+func Write(w io.Writer, cells cell.Cells) error {
+	nb := notebook{
+		Metadata: metadata{
+			KernelSpec:   &foxtrotKernel,
+			LanguageInfo: &languageInfo{Name: "wolfram", FileExtension: ".wl"}},
+		NBFormat:      nbformat,
+		NBFormatMinor: nbformatMinor,
+		Cells:         []nbCell{}}
+	code := -1
+	for _, c := range cells {
+		switch c.Type() {
+		case cell.H1, cell.H2, cell.H3, cell.H4, cell.H5, cell.H6:
+			level := int(c.Type()-cell.H1) + 1
+			src := strings.Repeat("#", level) + " " + c.Text()
+			nb.Cells = append(nb.Cells, nbCell{CellType: "markdown", Source: multiline(src)})
+			code = -1
+		case cell.Paragraph:
+			nb.Cells = append(nb.Cells, nbCell{CellType: "markdown", Source: multiline(c.Text())})
+			code = -1
+		case cell.Input, cell.Code:
+			nc := nbCell{
+				CellType: "code",
+				Source:   multiline(c.Text()),
+				Metadata: cellMetadata{Foxtrot: &foxtrotMetadata{Type: c.Type().String()}}}
+			if n := c.Prompt(); n > 0 {
+				nc.ExecutionCount = &n
+			}
+			nb.Cells = append(nb.Cells, nc)
+			code = len(nb.Cells) - 1
+		case cell.Output:
+			if code == -1 {
+				// An output without input still needs a code cell to live in.
+				nb.Cells = append(nb.Cells, nbCell{CellType: "code"})
+				code = len(nb.Cells) - 1
+			}
+			nb.Cells[code].Outputs = append(nb.Cells[code].Outputs, writeOutput(c))
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", " ")
+	return enc.Encode(nb)
+}
+
+func writeOutput(c cell.Cell) output {
+	if err := c.Err(); err != nil {
+		return output{
+			OutputType: "error",
+			EName:      "Error",
+			EValue:     err.Error(),
+			Traceback:  []string{err.Error()}}
+	}
+	txt := c.Text()
+	if ex := c.Out(); ex != nil {
+		txt = kernel.Format(ex, "InputForm")
+	}
+	o := output{
+		OutputType: "execute_result",
+		Data:       map[string]multiline{"text/plain": multiline(txt)},
+		Metadata:   &struct{}{}}
+	if n := c.Prompt(); n > 0 {
+		o.ExecutionCount = &n
+	}
+	return o
+}
@@ -0,0 +1,62 @@
+package ipynb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/kernel"
+	"testing"
+)
+
+func TestWriteRoundTrip(t *testing.T) {
+	h1 := cell.NewCell(cell.H1, "", nil)
+	h1.SetText("Foxtrot")
+	code := cell.NewCell(cell.Code, "", nil)
+	code.SetText("f[x_] := x^2")
+	in := cell.NewCell(cell.Input, kernel.InLabel(1), nil)
+	in.SetText("a/b")
+	in.SetPrompt(1)
+	out := cell.NewCell(cell.Output, kernel.OutLabel(1), nil)
+	out.SetPrompt(1)
+	ex, _ := kernel.Parse("a/b")
+	out.SetOut(ex)
+	failed := cell.NewCell(cell.Output, "", nil)
+	failed.SetErr(errors.New("failed"))
+
+	buf := new(bytes.Buffer)
+	err := Write(buf, cell.Cells{h1, code, in, out, failed})
+	assert.Nil(t, err)
+	cells, err := Read(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(cells))
+	assert.Equal(t, cell.H1, cells[0].Type())
+	assert.Equal(t, "Foxtrot", cells[0].Text())
+	assert.Equal(t, cell.Code, cells[1].Type())
+	assert.Equal(t, "f[x_] := x^2", cells[1].Text())
+	assert.Equal(t, cell.Input, cells[2].Type())
+	assert.Equal(t, "a/b", cells[2].Text())
+	assert.Equal(t, cell.Output, cells[3].Type())
+	assert.Equal(t, kernel.Format(ex, "InputForm"), cells[3].Text())
+	assert.NotNil(t, cells[4].Err())
+}
+
+func TestWriteUnnumberedOutput(t *testing.T) {
+	out := cell.NewCell(cell.Output, "", nil)
+	out.SetText("x")
+	buf := new(bytes.Buffer)
+	err := Write(buf, cell.Cells{out})
+	assert.Nil(t, err)
+	var nb struct {
+		Cells []struct {
+			Outputs []map[string]interface{}
+		}
+	}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &nb))
+	o := nb.Cells[0].Outputs[0]
+	assert.Equal(t, "execute_result", o["output_type"])
+	n, ok := o["execution_count"]
+	assert.True(t, ok)
+	assert.Nil(t, n)
+}
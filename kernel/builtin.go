@@ -0,0 +1,105 @@
+package kernel
+
+import (
+	"errors"
+	"fmt"
+	"github.com/corywalker/expreduce/expreduce/atoms"
+	api "github.com/corywalker/expreduce/pkg/expreduceapi"
+	"github.com/wrnrlr/foxtrot/graphics"
+	"path/filepath"
+	"strings"
+)
+
+// builtin implements a function that expreduce does not know about, it is defined in the System` context
+// of the EvalState and evaluated like any other definition, so it does not run inside Hold or after a semicolon
+// has discarded its value.
+type builtin func(k *Kernel, e *atoms.Expression) (api.Ex, error)
+
+var builtins = map[string]builtin{
+	"Export":      export,
+	"NotebookGet": notebookGet,
+	"NotebookPut": notebookPut,
+}
+
+// defineBuiltins adds the builtins to the EvalState of the kernel. A builtin that fails evaluates to $Failed
+// and its error is kept in failure, to be returned by eval.
+func (k *Kernel) defineBuiltins() {
+	defs := k.es.GetDefinedMap()
+	for name, fn := range builtins {
+		fn, name := fn, "System`"+name
+		def, _ := defs.Get(name)
+		def.LegacyEvalFn = func(e api.ExpressionInterface, es api.EvalStateInterface) api.Ex {
+			ex, err := fn(k, e.(*atoms.Expression))
+			if err != nil {
+				if k.failure == nil {
+					k.failure = err
+				}
+				return atoms.NewSymbol("System`$Failed")
+			}
+			return ex
+		}
+		def.Attributes.Protected = true
+		defs.Set(name, def)
+	}
+}
+
+// export writes an expression to a file, the format follows from the extension.
+//
+//	Export["circle.svg", Graphics[Circle[]]]
+func export(k *Kernel, e *atoms.Expression) (api.Ex, error) {
+	if e.Len() != 2 {
+		return nil, errors.New("Export[file, expr] needs a file name and an expression")
+	}
+	filename, ok := e.GetPart(1).(*atoms.String)
+	if !ok {
+		return nil, errors.New("Export[file, expr] needs a String as file name")
+	}
+	switch ext := filepath.Ext(filename.Val); ext {
+	case ".svg":
+		g, ok := e.GetPart(2).(*atoms.Expression)
+		if !ok {
+			return nil, errors.New("Export to .svg needs a Graphics[] expression")
+		}
+		gr, err := graphics.FromEx(g, &graphics.Style{})
+		if err != nil {
+			return nil, err
+		}
+		if err := gr.WriteSVGFile(filename.Val); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("Export to %q files is not supported", ext)
+	}
+	return filename, nil
+}
+
+// notebookGet returns the notebook of the evaluation as a Notebook[Cell[...], ...] expression.
+//
+//	NotebookGet[]
+func notebookGet(k *Kernel, e *atoms.Expression) (api.Ex, error) {
+	if e.Len() != 0 {
+		return nil, errors.New("NotebookGet[] takes no arguments")
+	}
+	if k.notebook == nil {
+		return nil, errors.New("NotebookGet[] is only available in a notebook")
+	}
+	return k.notebook.DeepCopy(), nil
+}
+
+// notebookPut replaces the cells of the notebook of the evaluation once it has finished.
+//
+//	NotebookPut[Append[NotebookGet[], Cell["Done", "Paragraph"]]]
+func notebookPut(k *Kernel, e *atoms.Expression) (api.Ex, error) {
+	if e.Len() != 1 {
+		return nil, errors.New("NotebookPut[nb] needs a Notebook[...] expression")
+	}
+	nb, ok := e.GetPart(1).(*atoms.Expression)
+	if !ok || !strings.HasSuffix(nb.HeadStr(), "`Notebook") {
+		return nil, errors.New("NotebookPut[nb] needs a Notebook[...] expression")
+	}
+	if k.notebook == nil {
+		return nil, errors.New("NotebookPut[nb] is only available in a notebook")
+	}
+	k.put = nb
+	return atoms.NewSymbol("System`Null"), nil
+}
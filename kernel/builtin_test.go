@@ -0,0 +1,77 @@
+package kernel
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportSVG(t *testing.T) {
+	dir, err := ioutil.TempDir("", "foxtrot")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "circle.svg")
+	k := NewKernel()
+	ex, err := k.Eval(`Export["` + filename + `", Graphics[{Circle[{0, 0}, 1]}]]`)
+	assert.Nil(t, err)
+	assert.Equal(t, `"`+filename+`"`, k.InputForm(ex))
+	b, err := ioutil.ReadFile(filename)
+	assert.Nil(t, err)
+	assert.Contains(t, string(b), `<circle cx="0" cy="0" r="1"`)
+}
+
+func TestExportUnsupported(t *testing.T) {
+	k := NewKernel()
+	_, err := k.Eval(`Export["circle.doc", Graphics[{Circle[]}]]`)
+	assert.NotNil(t, err)
+}
+
+func TestExportEvaluated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "foxtrot")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	k := NewKernel()
+	discarded := filepath.Join(dir, "discarded.svg")
+	ex, err := k.Eval(`Export["` + discarded + `", Graphics[{Circle[]}]];`)
+	assert.Nil(t, err)
+	assert.Equal(t, "Null", k.InputForm(ex))
+	_, err = os.Stat(discarded)
+	assert.Nil(t, err)
+
+	held := filepath.Join(dir, "held.svg")
+	_, err = k.Eval(`Hold[Export["` + held + `", Graphics[{Circle[]}]]]`)
+	assert.Nil(t, err)
+	_, err = os.Stat(held)
+	assert.True(t, os.IsNotExist(err))
+
+	assigned := filepath.Join(dir, "assigned.svg")
+	_, err = k.Eval(`x = Export["` + assigned + `", Graphics[{Circle[]}]]`)
+	assert.Nil(t, err)
+	assert.Nil(t, os.Remove(assigned))
+	ex, err = k.Eval("x")
+	assert.Nil(t, err)
+	assert.Equal(t, `"`+assigned+`"`, k.InputForm(ex))
+	_, err = os.Stat(assigned)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestNotebookGetPut(t *testing.T) {
+	w := NewWorker(NewKernel())
+	defer w.Close()
+	nb, err := Parse(`Notebook[Cell["Hello", "Paragraph"]]`)
+	assert.Nil(t, err)
+	w.SetNotebook(nb)
+	w.Submit("Length[NotebookGet[]]")
+	w.Submit(`NotebookPut[Append[NotebookGet[], Cell["Done", "Paragraph"]]]`)
+	rs := waitResults(w, 2)
+	assert.Nil(t, rs[0].Err)
+	assert.Equal(t, "1", w.kernel.InputForm(rs[0].Ex))
+	assert.Nil(t, rs[0].Notebook)
+	assert.Nil(t, rs[1].Err)
+	assert.Equal(t, `Notebook[Cell["Hello", "Paragraph"], Cell["Done", "Paragraph"]]`, w.kernel.InputForm(rs[1].Notebook))
+
+	_, err = NewKernel().Eval("NotebookGet[]")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,38 @@
+package kernel
+
+import (
+	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/theme"
+)
+
+// EvalCells evaluates every Input cell in order and returns the cells
+// with the previous Output cells replaced by fresh ones.
+// The returned errors contain one entry for every input that failed.
+func EvalCells(k *Kernel, cells cell.Cells, styles *theme.Styles) (cell.Cells, []error) {
+	var result cell.Cells
+	var errs []error
+	for _, c := range cells {
+		if c.Type() == cell.Output {
+			continue
+		}
+		result = append(result, c)
+		if c.Type() != cell.Input || c.Text() == "" {
+			continue
+		}
+		n := k.PromptCount()
+		c.SetLabel(InLabel(n))
+		c.SetPrompt(n)
+		out := cell.NewCell(cell.Output, OutLabel(n), styles)
+		out.SetPrompt(n)
+		ex, err := k.Eval(c.Text())
+		if err != nil {
+			out.SetErr(err)
+			errs = append(errs, err)
+		} else {
+			out.SetText(k.InputForm(ex))
+			out.SetOut(ex)
+		}
+		result = append(result, out)
+	}
+	return result, errs
+}
@@ -0,0 +1,229 @@
+package kernel
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/corywalker/expreduce/expreduce"
+	"github.com/corywalker/expreduce/expreduce/atoms"
+	"github.com/corywalker/expreduce/expreduce/parser"
+	api "github.com/corywalker/expreduce/pkg/expreduceapi"
+	"strings"
+	"sync"
+)
+
+// Kernel evaluates Foxtrot source against a single EvalState and keeps track of the prompt count,
+// notebooks that share a kernel evaluate one after the other.
+type Kernel struct {
+	es *expreduce.EvalState
+	// evalMu serializes the evaluations of the workers that share the kernel.
+	evalMu sync.Mutex
+	// failure is the error of the first builtin that failed in the current evaluation, notebook is
+	// the notebook that NotebookGet returns and put the one that was passed to NotebookPut.
+	failure       error
+	notebook, put api.Ex
+
+	mu          sync.Mutex
+	promptCount int
+	// running is the job that holds evalMu.
+	running *Job
+
+	builtinsOnce sync.Once
+	builtins     []string
+	// symbols are the symbols of the EvalState after the last evaluation.
+	symbols []definition
+}
+
+func NewKernel() *Kernel {
+	k := &Kernel{es: expreduce.NewEvalState(), promptCount: 1}
+	k.defineBuiltins()
+	k.snapshotSymbols()
+	return k
+}
+
+// EvalState returns the underlying expreduce state.
+func (k *Kernel) EvalState() *expreduce.EvalState {
+	return k.es
+}
+
+// PromptCount is the number of the next In[n] and Out[n] labels.
+func (k *Kernel) PromptCount() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.promptCount
+}
+
+// SetPromptCount continues the numbering of a notebook that was evaluated before.
+func (k *Kernel) SetPromptCount(n int) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if n < 1 {
+		n = 1
+	}
+	k.promptCount = n
+}
+
+// Eval parses and evaluates src and increments the prompt count, the error of a builtin like Export
+// that failed is returned as well.
+func (k *Kernel) Eval(src string) (api.Ex, error) {
+	r := k.eval(&Job{Src: src})
+	return r.Ex, r.Err
+}
+
+// eval evaluates the source of j, NotebookGet returns the notebook of j during the evaluation.
+func (k *Kernel) eval(j *Job) Result {
+	k.evalMu.Lock()
+	defer k.evalMu.Unlock()
+	k.mu.Lock()
+	r := Result{Job: j, Prompt: k.promptCount}
+	k.promptCount++
+	if j.aborted {
+		// The job was aborted while it waited for a job of another worker.
+		k.mu.Unlock()
+		r.Err = ErrAborted
+		return r
+	}
+	k.running = j
+	k.mu.Unlock()
+	k.evalSrc(j, &r)
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.running = nil
+	if j.aborted {
+		r.Ex, r.Err, r.Notebook = nil, ErrAborted, nil
+		setInterrupted(k, false)
+	}
+	return r
+}
+
+// abort interrupts j when it holds the kernel, when it is still waiting for the kernel it is not evaluated.
+// When the kernel does not support interrupts the result of the evaluation is discarded once it finishes.
+func (k *Kernel) abort(j *Job) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	j.aborted = true
+	if k.running == j {
+		setInterrupted(k, true)
+	}
+}
+
+// evalSrc evaluates the source of j into r, the caller holds evalMu.
+func (k *Kernel) evalSrc(j *Job, r *Result) {
+	src := parser.ReplaceSyms(j.Src)
+	buf := bytes.NewBufferString(src)
+	ex, err := parser.InterpBuf(buf, "nofile", k.es)
+	if err != nil {
+		r.Err = err
+		return
+	}
+	k.notebook = j.notebook
+	r.Ex = k.es.Eval(ex)
+	if k.es.HasThrown() {
+		// An uncaught Throw is kept by the EvalState and would end every later evaluation.
+		thrown := k.es.Thrown()
+		k.es.Throw(nil)
+		r.Ex = atoms.NewExpression([]api.Ex{atoms.NewSymbol("System`Hold"), thrown})
+		if k.failure == nil {
+			k.failure = fmt.Errorf("%s returned to top level but uncaught", k.InputForm(thrown))
+		}
+	}
+	r.Err, r.Notebook = k.failure, k.put
+	k.notebook, k.put, k.failure = nil, nil, nil
+	k.snapshotSymbols()
+}
+
+// Builtins returns the names of the symbols in the System` context and of Foxtrot's own builtins,
+// the editor highlights them in input cells. The names are looked up once, the first call waits
+// for the evaluation that is running.
+func (k *Kernel) Builtins() []string {
+	k.builtinsOnce.Do(func() {
+		k.evalMu.Lock()
+		defer k.evalMu.Unlock()
+		k.builtins = k.lookupBuiltins()
+	})
+	return k.builtins
+}
+
+func (k *Kernel) lookupBuiltins() []string {
+	var names []string
+	for name := range builtins {
+		names = append(names, name)
+	}
+	ex, err := parser.InterpBuf(bytes.NewBufferString("Names[\"System`*\"]"), "nofile", k.es)
+	if err != nil {
+		return names
+	}
+	list, ok := k.es.Eval(ex).(*atoms.Expression)
+	if !ok || list.HeadStr() != "System`List" {
+		return names
+	}
+	for _, part := range list.Parts[1:] {
+		if s, ok := part.(*atoms.String); ok {
+			names = append(names, s.Val[strings.LastIndex(s.Val, "`")+1:])
+		}
+	}
+	return names
+}
+
+// InputForm formats ex the same way it would be typed in an input cell.
+func (k *Kernel) InputForm(ex api.Ex) string {
+	return escapeStrings(ex).StringForm(expreduce.ActualStringFormArgsFull("InputForm", k.es))
+}
+
+var stringEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+// escapeStrings returns ex with the special characters in its strings escaped, expreduce only puts quotes
+// around the value of a string so InputForm and FullForm would not parse again otherwise.
+func escapeStrings(ex api.Ex) api.Ex {
+	switch e := ex.(type) {
+	case *atoms.String:
+		return atoms.NewString(stringEscaper.Replace(e.Val))
+	case *atoms.Expression:
+		parts := make([]api.Ex, len(e.Parts))
+		for i, part := range e.Parts {
+			parts[i] = escapeStrings(part)
+		}
+		return atoms.NewExpression(parts)
+	}
+	return ex
+}
+
+func InLabel(n int) string {
+	return fmt.Sprintf("In[%d]:= ", n)
+}
+
+func OutLabel(n int) string {
+	return fmt.Sprintf("Out[%d]= ", n)
+}
+
+// shared is used to parse and format expressions outside of a notebook,
+// it is created once because a new EvalState is slow to set up.
+var shared struct {
+	sync.Mutex
+	k *Kernel
+}
+
+func sharedKernel() *Kernel {
+	if shared.k == nil {
+		shared.k = NewKernel()
+	}
+	return shared.k
+}
+
+// Parse src into an expression without evaluating it.
+func Parse(src string) (api.Ex, error) {
+	shared.Lock()
+	defer shared.Unlock()
+	buf := bytes.NewBufferString(parser.ReplaceSyms(src))
+	return parser.InterpBuf(buf, "nofile", sharedKernel().es)
+}
+
+// Format ex in the given form, for example "InputForm" or "FullForm".
+// The strings in both forms are escaped so the result can be parsed again.
+func Format(ex api.Ex, form string) string {
+	shared.Lock()
+	defer shared.Unlock()
+	if form == "InputForm" || form == "FullForm" {
+		ex = escapeStrings(ex)
+	}
+	return ex.StringForm(expreduce.ActualStringFormArgsFull(form, sharedKernel().es))
+}
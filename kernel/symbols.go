@@ -0,0 +1,117 @@
+package kernel
+
+import (
+	"github.com/corywalker/expreduce/expreduce"
+	"github.com/corywalker/expreduce/expreduce/atoms"
+	api "github.com/corywalker/expreduce/pkg/expreduceapi"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Symbol is a builtin or user-defined symbol of the kernel.
+type Symbol struct {
+	Name  string
+	Usage string
+	// Function is set when the symbol has definitions that take arguments.
+	Function bool
+}
+
+// usages maps the names of expreduce's builtins to their usage message.
+var usages struct {
+	once sync.Once
+	m    map[string]string
+}
+
+func usage(name string) string {
+	usages.once.Do(func() {
+		usages.m = map[string]string{}
+		for _, set := range expreduce.GetAllDefinitions() {
+			for _, def := range set.Defs {
+				usages.m[def.Name] = def.Usage
+			}
+		}
+	})
+	return usages.m[name]
+}
+
+// definition is a symbol of the defined map of the EvalState, Function is set when it has a rule or
+// an evaluation function that takes arguments.
+type definition struct {
+	name     string
+	function bool
+}
+
+// snapshotSymbols records the symbols of the EvalState that Symbols searches, it is called with evalMu
+// held after every evaluation so Symbols never reads the EvalState while a worker is evaluating.
+func (k *Kernel) snapshotSymbols() {
+	defs := k.es.GetDefinedMap()
+	var snapshot []definition
+	for _, key := range defs.Keys() {
+		if !strings.HasPrefix(key, "System`") && !strings.HasPrefix(key, "Global`") {
+			continue
+		}
+		def, _ := defs.Get(key)
+		snapshot = append(snapshot, definition{key[strings.LastIndex(key, "`")+1:], takesArguments(def)})
+	}
+	k.mu.Lock()
+	k.symbols = snapshot
+	k.mu.Unlock()
+}
+
+// takesArguments reports whether def has an evaluation function or a rule like f[x_] := ...,
+// the value of a symbol is kept as the rule HoldPattern[x] -> value.
+func takesArguments(def api.Def) bool {
+	if def.LegacyEvalFn != nil {
+		return true
+	}
+	for _, dv := range def.Downvalues {
+		lhs, ok := dv.Rule.GetPart(1).(*atoms.Expression)
+		if !ok || lhs.HeadStr() != "System`HoldPattern" || lhs.Len() != 1 {
+			continue
+		}
+		if _, ok := lhs.GetPart(1).(*atoms.Expression); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// maxSymbols is the maximum number of symbols returned by Symbols.
+const maxSymbols = 50
+
+// Symbols returns the builtin and user-defined symbols that start with prefix, the symbols that match the case
+// of the prefix come first. The symbols are those of the EvalState at the end of the last evaluation,
+// so Symbols does not wait for an evaluation that is running.
+func (k *Kernel) Symbols(prefix string) []Symbol {
+	k.mu.Lock()
+	snapshot := k.symbols
+	k.mu.Unlock()
+	seen := map[string]bool{}
+	var symbols []Symbol
+	add := func(name string, function bool) {
+		if seen[name] || !strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix)) {
+			return
+		}
+		seen[name] = true
+		u := usage(name)
+		symbols = append(symbols, Symbol{Name: name, Usage: u, Function: function || strings.Contains(u, name+"[")})
+	}
+	for _, def := range snapshot {
+		add(def.name, def.function)
+	}
+	for name := range builtins {
+		add(name, true)
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		a, b := strings.HasPrefix(symbols[i].Name, prefix), strings.HasPrefix(symbols[j].Name, prefix)
+		if a != b {
+			return a
+		}
+		return symbols[i].Name < symbols[j].Name
+	})
+	if len(symbols) > maxSymbols {
+		symbols = symbols[:maxSymbols]
+	}
+	return symbols
+}
@@ -0,0 +1,48 @@
+package kernel
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSymbols(t *testing.T) {
+	k := NewKernel()
+	_, err := k.Eval("mySquare[x_] := x^2")
+	assert.Nil(t, err)
+	_, err = k.Eval("myConstant = 2")
+	assert.Nil(t, err)
+	assert.Equal(t, []Symbol{{Name: "myConstant"}, {Name: "mySquare", Function: true}}, k.Symbols("my"))
+
+	symbols := k.Symbols("sin")
+	assert.NotEmpty(t, symbols)
+	var sin Symbol
+	for _, s := range symbols {
+		if s.Name == "Sin" {
+			sin = s
+		}
+	}
+	assert.True(t, sin.Function)
+}
+
+func TestSymbolsWhileEvaluating(t *testing.T) {
+	k := NewKernel()
+	w := NewWorker(k)
+	defer w.Close()
+	w.Submit("myCounter = 0")
+	waitResults(w, 1)
+	j := w.Submit("While[True, myCounter++]")
+	for w.State(j) != Running {
+		time.Sleep(time.Millisecond)
+	}
+	found := make(chan []Symbol)
+	go func() { found <- k.Symbols("myC") }()
+	select {
+	case symbols := <-found:
+		assert.Equal(t, []Symbol{{Name: "myCounter"}}, symbols)
+	case <-time.After(time.Second):
+		t.Error("Symbols waits for the evaluation")
+	}
+	w.Abort()
+	waitResults(w, 1)
+}
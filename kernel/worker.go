@@ -0,0 +1,269 @@
+package kernel
+
+import (
+	"errors"
+	"github.com/corywalker/expreduce/expreduce/atoms"
+	api "github.com/corywalker/expreduce/pkg/expreduceapi"
+	"sync"
+)
+
+var ErrAborted = errors.New("evaluation aborted")
+
+type JobState int
+
+const (
+	Queued JobState = iota
+	Running
+	Done
+	Cancelled
+)
+
+// Job is a piece of source waiting to be evaluated by a Worker.
+type Job struct {
+	Src   string
+	state JobState
+	// batch is the number of the batch the job was submitted in, zero for a job on its own.
+	batch int
+	// notebook is the Notebook[...] expression of the notebook at the time the job was submitted.
+	notebook api.Ex
+	// aborted is set by Kernel.abort, it is guarded by the mu of the kernel.
+	aborted bool
+}
+
+// Result of an evaluated Job, Prompt is the number used for its In[n] and Out[n] labels.
+// Notebook is the expression passed to NotebookPut, nil when it was not called.
+type Result struct {
+	Job      *Job
+	Prompt   int
+	Ex       api.Ex
+	Err      error
+	Notebook api.Ex
+}
+
+// interrupter is implemented by kernels that can stop an evaluation that is in progress,
+// SetInterrupted is called while another goroutine evaluates so the flag must be safe for concurrent use.
+// The expreduce release in go.mod only sets the flag on SIGINT, its EvalState is not an interrupter.
+type interrupter interface {
+	SetInterrupted(bool)
+}
+
+// setInterrupted sets the interrupt flag of the EvalState of k when it can be interrupted.
+func setInterrupted(k *Kernel, interrupted bool) {
+	var es interface{} = k.es
+	if i, ok := es.(interrupter); ok {
+		i.SetInterrupted(interrupted)
+	}
+}
+
+// Worker evaluates jobs one after the other on its own goroutine,
+// so that a long evaluation does not block the caller.
+type Worker struct {
+	kernel *Kernel
+
+	mu      sync.Mutex
+	queue   []*Job
+	running *Job
+	results []Result
+	closed  bool
+	batches int
+	// notebook is given to the jobs that are submitted.
+	notebook api.Ex
+
+	wake    chan struct{}
+	updated chan struct{}
+}
+
+func NewWorker(k *Kernel) *Worker {
+	w := &Worker{
+		kernel:  k,
+		wake:    make(chan struct{}, 1),
+		updated: make(chan struct{}, 1)}
+	go w.loop()
+	return w
+}
+
+// SetNotebook sets the Notebook[...] expression that NotebookGet returns for the jobs that are submitted next.
+func (w *Worker) SetNotebook(nb api.Ex) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.notebook = nb
+}
+
+// Submit adds src to the end of the queue.
+func (w *Worker) Submit(src string) *Job {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	j := &Job{Src: src, state: Queued, notebook: w.notebook}
+	if w.closed {
+		j.state = Cancelled
+		return j
+	}
+	w.queue = append(w.queue, j)
+	signal(w.wake)
+	return j
+}
+
+// SubmitBatch adds the sources to the end of the queue, when the evaluation of one of them fails
+// the jobs of the batch that are still queued are cancelled. An evaluation fails with a syntax error,
+// an uncaught Throw, a builtin that returns an error or $Failed as its result.
+func (w *Worker) SubmitBatch(srcs ...string) []*Job {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.batches++
+	jobs := make([]*Job, len(srcs))
+	for i, src := range srcs {
+		jobs[i] = &Job{Src: src, state: Queued, batch: w.batches, notebook: w.notebook}
+		if w.closed {
+			jobs[i].state = Cancelled
+		}
+	}
+	if !w.closed {
+		w.queue = append(w.queue, jobs...)
+		signal(w.wake)
+	}
+	return jobs
+}
+
+// Cancel removes a job from the queue, it returns false when the job is already running or done.
+func (w *Worker) Cancel(j *Job) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, q := range w.queue {
+		if q == j {
+			w.queue = append(w.queue[:i], w.queue[i+1:]...)
+			j.state = Cancelled
+			return true
+		}
+	}
+	return false
+}
+
+// CancelAll removes all jobs that are still waiting in the queue.
+func (w *Worker) CancelAll() []*Job {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	jobs := w.queue
+	for _, j := range jobs {
+		j.state = Cancelled
+	}
+	w.queue = nil
+	return jobs
+}
+
+// Abort interrupts the running job, its result will have ErrAborted as error.
+// Jobs of other workers that share the kernel are not interrupted.
+func (w *Worker) Abort() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.running != nil {
+		w.kernel.abort(w.running)
+	}
+}
+
+// State returns the current state of j.
+func (w *Worker) State(j *Job) JobState {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return j.state
+}
+
+// Pending is the number of jobs that are queued or running.
+func (w *Worker) Pending() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := len(w.queue)
+	if w.running != nil {
+		n++
+	}
+	return n
+}
+
+// Results returns the results that are available since the last call.
+func (w *Worker) Results() []Result {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	rs := w.results
+	w.results = nil
+	return rs
+}
+
+// Updated receives a value whenever a job changes state, it is closed once the worker has stopped.
+func (w *Worker) Updated() <-chan struct{} {
+	return w.updated
+}
+
+// Close stops the worker after the running job has finished.
+func (w *Worker) Close() {
+	w.CancelAll()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.closed = true
+		close(w.wake)
+	}
+}
+
+func (w *Worker) loop() {
+	for range w.wake {
+		for j := w.next(); j != nil; j = w.next() {
+			w.finish(w.kernel.eval(j))
+		}
+	}
+	close(w.updated)
+}
+
+func (w *Worker) next() *Job {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.queue) == 0 {
+		return nil
+	}
+	j := w.queue[0]
+	w.queue = w.queue[1:]
+	j.state = Running
+	w.running = j
+	signal(w.updated)
+	return j
+}
+
+func (w *Worker) finish(r Result) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	r.Job.state = Done
+	if failed(r) && r.Job.batch != 0 {
+		w.cancelBatch(r.Job.batch)
+	}
+	w.running = nil
+	w.results = append(w.results, r)
+	signal(w.updated)
+}
+
+// failed reports whether the evaluation of a job failed: it has an error or its result is $Failed.
+// Messages are not detected, expreduce leaves Message unevaluated.
+func failed(r Result) bool {
+	if r.Err != nil {
+		return true
+	}
+	sym, ok := r.Ex.(*atoms.Symbol)
+	return ok && sym.Name == "System`$Failed"
+}
+
+// cancelBatch removes the queued jobs of a batch, the caller holds the lock.
+func (w *Worker) cancelBatch(batch int) {
+	queue := w.queue[:0]
+	for _, j := range w.queue {
+		if j.batch == batch {
+			j.state = Cancelled
+		} else {
+			queue = append(queue, j)
+		}
+	}
+	w.queue = queue
+}
+
+func signal(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,184 @@
+package kernel
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func waitResults(w *Worker, n int) []Result {
+	var rs []Result
+	for len(rs) < n {
+		<-w.Updated()
+		rs = append(rs, w.Results()...)
+	}
+	return rs
+}
+
+func TestWorkerEval(t *testing.T) {
+	w := NewWorker(NewKernel())
+	defer w.Close()
+	j := w.Submit("1+1")
+	rs := waitResults(w, 1)
+	assert.Equal(t, j, rs[0].Job)
+	assert.Equal(t, 1, rs[0].Prompt)
+	assert.Nil(t, rs[0].Err)
+	assert.Equal(t, "2", w.kernel.InputForm(rs[0].Ex))
+	assert.Equal(t, Done, w.State(j))
+}
+
+func TestWorkerOrder(t *testing.T) {
+	w := NewWorker(NewKernel())
+	defer w.Close()
+	w.Submit("a = 2")
+	w.Submit("a + 1")
+	rs := waitResults(w, 2)
+	assert.Equal(t, 2, rs[1].Prompt)
+	assert.Equal(t, "3", w.kernel.InputForm(rs[1].Ex))
+}
+
+func TestWorkerCancel(t *testing.T) {
+	w := &Worker{kernel: NewKernel(), wake: make(chan struct{}, 1), updated: make(chan struct{}, 1)}
+	j1 := w.Submit("1")
+	j2 := w.Submit("2")
+	assert.True(t, w.Cancel(j2))
+	assert.Equal(t, Cancelled, w.State(j2))
+	assert.False(t, w.Cancel(j2))
+	assert.Equal(t, 1, w.Pending())
+	assert.Equal(t, []*Job{j1}, w.CancelAll())
+	assert.Equal(t, 0, w.Pending())
+}
+
+func TestWorkerSharedKernel(t *testing.T) {
+	k := NewKernel()
+	w1, w2 := NewWorker(k), NewWorker(k)
+	defer w1.Close()
+	defer w2.Close()
+	w1.Submit("a = 2")
+	w2.Submit("b = 3")
+	waitResults(w1, 1)
+	waitResults(w2, 1)
+	w1.Submit("a + b")
+	rs := waitResults(w1, 1)
+	assert.Equal(t, 3, rs[0].Prompt)
+	assert.Equal(t, "5", k.InputForm(rs[0].Ex))
+}
+
+func TestWorkerBatch(t *testing.T) {
+	w := NewWorker(NewKernel())
+	defer w.Close()
+	jobs := w.SubmitBatch("a = 1", "a +", "a + 2")
+	other := w.Submit("a + 3")
+	rs := waitResults(w, 3)
+	assert.Equal(t, jobs[0], rs[0].Job)
+	assert.Equal(t, jobs[1], rs[1].Job)
+	assert.NotNil(t, rs[1].Err)
+	assert.Equal(t, Cancelled, w.State(jobs[2]))
+	assert.Equal(t, other, rs[2].Job)
+	assert.Equal(t, "4", w.kernel.InputForm(rs[2].Ex))
+}
+
+func TestWorkerBatchRuntimeFailure(t *testing.T) {
+	w := NewWorker(NewKernel())
+	defer w.Close()
+	jobs := w.SubmitBatch("a = 1", "Throw[a]", "a = 2")
+	rs := waitResults(w, 2)
+	assert.NotNil(t, rs[1].Err)
+	assert.Equal(t, "Hold[Throw[1]]", w.kernel.InputForm(rs[1].Ex))
+	assert.Equal(t, Cancelled, w.State(jobs[2]))
+
+	jobs = w.SubmitBatch(`Export["a.doc", 1]`, "a = 3")
+	rs = waitResults(w, 1)
+	assert.Equal(t, "$Failed", w.kernel.InputForm(rs[0].Ex))
+	assert.Equal(t, Cancelled, w.State(jobs[1]))
+
+	jobs = w.SubmitBatch("$Failed", "a = 4")
+	rs = waitResults(w, 1)
+	assert.Nil(t, rs[0].Err)
+	assert.Equal(t, Cancelled, w.State(jobs[1]))
+
+	w.Submit("a")
+	rs = waitResults(w, 1)
+	assert.Equal(t, "1", w.kernel.InputForm(rs[0].Ex))
+}
+
+func TestWorkerAbort(t *testing.T) {
+	k := NewKernel()
+	var es interface{} = k.es
+	if _, ok := es.(interrupter); !ok {
+		t.Skip("the EvalState can not be interrupted")
+	}
+	w := NewWorker(k)
+	defer w.Close()
+	j := w.Submit("While[True, i++]")
+	for w.State(j) != Running {
+		<-w.Updated()
+	}
+	w.Abort()
+	done := make(chan []Result)
+	go func() { done <- waitResults(w, 1) }()
+	select {
+	case rs := <-done:
+		assert.Equal(t, j, rs[0].Job)
+		assert.Equal(t, ErrAborted, rs[0].Err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("the running job was not interrupted")
+	}
+	w.Submit("1+1")
+	rs := waitResults(w, 1)
+	assert.Equal(t, "2", w.kernel.InputForm(rs[0].Ex))
+}
+
+func TestWorkerAbortSharedKernel(t *testing.T) {
+	k := NewKernel()
+	var es interface{} = k.es
+	if _, ok := es.(interrupter); !ok {
+		t.Skip("the EvalState can not be interrupted")
+	}
+	a, b := NewWorker(k), NewWorker(k)
+	defer a.Close()
+	defer b.Close()
+	jb := b.Submit("While[True, i++]")
+	for !k.isRunning(jb) {
+		time.Sleep(time.Millisecond)
+	}
+	ja := a.Submit("i = 0")
+	for a.State(ja) != Running {
+		<-a.Updated()
+	}
+	a.Abort()
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, k.isRunning(jb), "the job of the other worker was interrupted")
+	b.Abort()
+	rs := waitResults(b, 1)
+	assert.Equal(t, jb, rs[0].Job)
+	assert.Equal(t, ErrAborted, rs[0].Err)
+	rs = waitResults(a, 1)
+	assert.Equal(t, ja, rs[0].Job)
+	assert.Equal(t, ErrAborted, rs[0].Err)
+	a.Submit("i")
+	rs = waitResults(a, 1)
+	assert.NotEqual(t, "0", k.InputForm(rs[0].Ex), "the aborted job was evaluated")
+}
+
+// isRunning reports whether j holds the kernel.
+func (k *Kernel) isRunning(j *Job) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.running == j
+}
+
+func TestBuiltinsWhileEvaluating(t *testing.T) {
+	k := NewKernel()
+	w := NewWorker(k)
+	defer w.Close()
+	j := w.Submit("Do[f[i] = i, {i, 3000}]")
+	for w.State(j) == Queued {
+		time.Sleep(time.Millisecond)
+	}
+	names := k.Builtins()
+	assert.Contains(t, names, "Sin")
+	assert.Contains(t, names, "Export")
+	waitResults(w, 1)
+	assert.Equal(t, &names[0], &k.Builtins()[0])
+}
@@ -0,0 +1,58 @@
+package nbx
+
+import (
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// writeAtomic writes filename with write, the content goes to a temporary file in the same directory
+// that replaces filename once it is complete, so a crash can not leave a truncated file behind.
+// The mode of an existing file is kept, a new file is created with 0666 less the umask like os.Create.
+func writeAtomic(filename string, write func(w io.Writer) error) error {
+	if p, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = p
+	}
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+	file, err := createTemp(dir, base)
+	if err != nil {
+		return err
+	}
+	tmp := file.Name()
+	defer os.Remove(tmp)
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if info, err := os.Stat(filename); err == nil {
+		if err := os.Chmod(tmp, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp, filename)
+}
+
+// createTemp creates a new hidden file for base in dir, unlike ioutil.TempFile its mode is
+// 0666 before the umask is applied.
+func createTemp(dir, base string) (*os.File, error) {
+	for i := 0; ; i++ {
+		name := filepath.Join(dir, "."+base+".tmp"+strconv.Itoa(rand.Int()))
+		file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if os.IsExist(err) && i < 100 {
+			continue
+		}
+		return file, err
+	}
+}
@@ -0,0 +1,56 @@
+package nbx
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nbx")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	p := filepath.Join(dir, "a.nbx")
+	assert.Nil(t, ioutil.WriteFile(p, []byte("old"), 0600))
+	err = writeAtomic(p, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return errors.New("failed")
+	})
+	assert.NotNil(t, err)
+	b, _ := ioutil.ReadFile(p)
+	assert.Equal(t, "old", string(b))
+	err = writeAtomic(p, func(w io.Writer) error {
+		_, err := io.WriteString(w, "new")
+		return err
+	})
+	assert.Nil(t, err)
+	b, _ = ioutil.ReadFile(p)
+	assert.Equal(t, "new", string(b))
+	info, _ := os.Stat(p)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	files, _ := ioutil.ReadDir(dir)
+	assert.Equal(t, 1, len(files))
+}
+
+func TestWriteAtomicNewFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nbx")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	created := filepath.Join(dir, "created")
+	file, err := os.Create(created)
+	assert.Nil(t, err)
+	file.Close()
+	want, _ := os.Stat(created)
+	p := filepath.Join(dir, "a.nbx")
+	assert.Nil(t, writeAtomic(p, func(w io.Writer) error {
+		_, err := io.WriteString(w, "new")
+		return err
+	}))
+	info, err := os.Stat(p)
+	assert.Nil(t, err)
+	assert.Equal(t, want.Mode().Perm(), info.Mode().Perm())
+}
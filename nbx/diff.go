@@ -0,0 +1,267 @@
+package nbx
+
+import (
+	"fmt"
+	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/kernel"
+	"strings"
+)
+
+// Op is the kind of change of a cell between two versions of a notebook.
+type Op int
+
+const (
+	Equal Op = iota
+	Added
+	Removed
+	Modified
+	Moved
+)
+
+func (o Op) String() string {
+	switch o {
+	case Added:
+		return "Added"
+	case Removed:
+		return "Removed"
+	case Modified:
+		return "Modified"
+	case Moved:
+		return "Moved"
+	}
+	return "Equal"
+}
+
+// Change is a cell in the alignment of two versions of a notebook, the output cells that follow
+// an input cell belong to it and have no change of their own. Old and New are the indexes of the cell
+// in both versions and -1 when it is missing from one.
+type Change struct {
+	Op       Op
+	Old, New int
+	// Input is set when the content of a modified cell changed and Output when its output cells changed,
+	// a cell that moved can have changed outputs as well.
+	Input, Output bool
+}
+
+// Diff aligns the cells of old and new, cells with the same type and content are equal.
+// A removed cell with the same content as an added cell has moved, one that is followed by
+// an added cell of the same type is modified.
+func Diff(old, new cell.Cells) []Change {
+	a, b := heads(old), heads(new)
+	ka, kb := cellKeys(old, a), cellKeys(new, b)
+	// lcs[i][j] is the length of the longest common subsequence of ka[i:] and kb[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if ka[i] == kb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	// The walk along the longest common subsequence is the alignment without moves.
+	var walk []Change
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && ka[i] == kb[j]:
+			walk = append(walk, Change{Op: Equal, Old: a[i], New: b[j]})
+			i, j = i+1, j+1
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			walk = append(walk, Change{Op: Removed, Old: a[i], New: -1})
+			i++
+		default:
+			walk = append(walk, Change{Op: Added, Old: -1, New: b[j]})
+			j++
+		}
+	}
+	// A removed cell moved to the place of an added cell with the same content.
+	moved, movedTo := map[int]bool{}, map[int]int{}
+	for _, ad := range walk {
+		if ad.Op != Added {
+			continue
+		}
+		for _, r := range walk {
+			if r.Op == Removed && !moved[r.Old] && sameKey(old, new, r.Old, ad.New) {
+				moved[r.Old], movedTo[ad.New] = true, r.Old
+				break
+			}
+		}
+	}
+	var changes, removed, added []Change
+	flush := func() {
+		changes = append(changes, pairModified(old, new, removed, added)...)
+		removed, added = nil, nil
+	}
+	for _, c := range walk {
+		switch c.Op {
+		case Equal:
+			flush()
+			if !sameOutputs(old, new, c.Old, c.New) {
+				c.Op, c.Output = Modified, true
+			}
+			changes = append(changes, c)
+		case Removed:
+			if !moved[c.Old] {
+				removed = append(removed, c)
+			}
+		case Added:
+			if o, ok := movedTo[c.New]; ok {
+				flush()
+				changes = append(changes, Change{Op: Moved, Old: o, New: c.New, Output: !sameOutputs(old, new, o, c.New)})
+			} else {
+				added = append(added, c)
+			}
+		}
+	}
+	flush()
+	return changes
+}
+
+// pairModified turns the removed and added cells between two equal cells into modified cells
+// when they have the same type, in the order in which they appear.
+func pairModified(old, new cell.Cells, removed, added []Change) []Change {
+	var changes []Change
+	for len(removed) > 0 && len(added) > 0 {
+		r, a := removed[0], added[0]
+		switch {
+		case old[r.Old].Type() == new[a.New].Type():
+			changes = append(changes, Change{Op: Modified, Old: r.Old, New: a.New, Input: true,
+				Output: !sameOutputs(old, new, r.Old, a.New)})
+			removed, added = removed[1:], added[1:]
+		case r.Old <= a.New:
+			changes = append(changes, r)
+			removed = removed[1:]
+		default:
+			changes = append(changes, a)
+			added = added[1:]
+		}
+	}
+	changes = append(changes, removed...)
+	return append(changes, added...)
+}
+
+// heads are the indexes of the cells that are not output cells, an output cell at the start
+// of a notebook has no input cell and is a head as well.
+func heads(cells cell.Cells) []int {
+	var hs []int
+	for i, c := range cells {
+		if i == 0 || c.Type() != cell.Output {
+			hs = append(hs, i)
+		}
+	}
+	return hs
+}
+
+// Outputs are the output cells that follow the cell at index i.
+func Outputs(cells cell.Cells, i int) cell.Cells {
+	j := i + 1
+	for j < len(cells) && cells[j].Type() == cell.Output {
+		j++
+	}
+	return cells[i+1 : j]
+}
+
+// unit is the cell at index i with its output cells.
+func unit(cells cell.Cells, i int) cell.Cells {
+	return cells[i : i+1+len(Outputs(cells, i))]
+}
+
+type cellKey struct {
+	typ     cell.Type
+	content string
+}
+
+func cellKeys(cells cell.Cells, heads []int) []cellKey {
+	keys := make([]cellKey, len(heads))
+	for i, h := range heads {
+		keys[i] = cellKey{cells[h].Type(), Content(cells[h])}
+	}
+	return keys
+}
+
+func sameKey(a, b cell.Cells, i, j int) bool {
+	return a[i].Type() == b[j].Type() && Content(a[i]) == Content(b[j])
+}
+
+func sameOutputs(a, b cell.Cells, i, j int) bool {
+	return sameCells(Outputs(a, i), Outputs(b, j))
+}
+
+func sameCells(a, b cell.Cells) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !sameKey(a, b, i, i) {
+			return false
+		}
+	}
+	return true
+}
+
+// Content is the text of a cell, the FullForm of the expression of an output cell or its error.
+func Content(c cell.Cell) string {
+	if c.Type() != cell.Output {
+		return c.Text()
+	}
+	if ex := c.Out(); ex != nil {
+		return kernel.Format(ex, "FullForm")
+	} else if err := c.Err(); err != nil {
+		return err.Error()
+	}
+	return c.Text()
+}
+
+// DiffLine is a cell in the text of a diff, Op is Equal for a cell that is shown for context,
+// Removed or Added for the old and new version of a cell and Moved for a cell that moved.
+type DiffLine struct {
+	Op   Op
+	Text string
+}
+
+// DiffLines describes the changes from old to new one cell per line, the content of an equal cell
+// is shortened to its first line.
+func DiffLines(old, new cell.Cells, changes []Change) []DiffLine {
+	var lines []DiffLine
+	add := func(op Op, c cell.Cell, suffix string) {
+		lines = append(lines, DiffLine{op, c.Type().String() + ": " + Content(c) + suffix})
+	}
+	addAll := func(op Op, cells cell.Cells) {
+		for _, c := range cells {
+			add(op, c, "")
+		}
+	}
+	for _, c := range changes {
+		switch {
+		case c.Op == Equal || (c.Op == Modified && !c.Input):
+			text := Content(old[c.Old])
+			if i := strings.IndexByte(text, '\n'); i != -1 {
+				text = text[:i] + " …"
+			}
+			lines = append(lines, DiffLine{Equal, old[c.Old].Type().String() + ": " + text})
+		case c.Op == Removed:
+			addAll(Removed, unit(old, c.Old))
+			continue
+		case c.Op == Added:
+			addAll(Added, unit(new, c.New))
+			continue
+		case c.Op == Moved:
+			add(Moved, new[c.New], fmt.Sprintf(" (moved from cell %d to %d)", c.Old+1, c.New+1))
+		default:
+			add(Removed, old[c.Old], "")
+			add(Added, new[c.New], "")
+		}
+		if c.Output {
+			addAll(Removed, Outputs(old, c.Old))
+			addAll(Added, Outputs(new, c.New))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,77 @@
+package nbx
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/wrnrlr/foxtrot/cell"
+	"testing"
+)
+
+// newCells returns cells of pairs of a type and a content, the content of an output cell is its error.
+func newCells(cells ...interface{}) cell.Cells {
+	var cs cell.Cells
+	for i := 0; i < len(cells); i += 2 {
+		c := cell.NewCell(cells[i].(cell.Type), "", nil)
+		if c.Type() == cell.Output {
+			c.SetErr(errors.New(cells[i+1].(string)))
+		} else {
+			c.SetText(cells[i+1].(string))
+		}
+		cs = append(cs, c)
+	}
+	return cs
+}
+
+func contents(cells cell.Cells) []string {
+	var s []string
+	for _, c := range cells {
+		s = append(s, Content(c))
+	}
+	return s
+}
+
+func TestDiff(t *testing.T) {
+	old := newCells(cell.H1, "Title", cell.Input, "1+1", cell.Input, "x=2", cell.Paragraph, "End")
+	new := newCells(cell.H1, "Title", cell.Input, "1+2", cell.Paragraph, "Note", cell.Paragraph, "End")
+	assert.Equal(t, []Change{
+		{Op: Equal, Old: 0, New: 0},
+		{Op: Modified, Old: 1, New: 1, Input: true},
+		{Op: Removed, Old: 2, New: -1},
+		{Op: Added, Old: -1, New: 2},
+		{Op: Equal, Old: 3, New: 3},
+	}, Diff(old, new))
+}
+
+func TestDiffEmpty(t *testing.T) {
+	cells := newCells(cell.Input, "a", cell.Input, "b")
+	assert.Equal(t, []Change{{Op: Added, Old: -1, New: 0}, {Op: Added, Old: -1, New: 1}}, Diff(nil, cells))
+	assert.Equal(t, []Change{{Op: Removed, Old: 0, New: -1}, {Op: Removed, Old: 1, New: -1}}, Diff(cells, nil))
+	assert.Equal(t, []Change{{Op: Equal, Old: 0, New: 0}, {Op: Equal, Old: 1, New: 1}}, Diff(cells, cells))
+}
+
+func TestDiffOutputs(t *testing.T) {
+	old := newCells(cell.Input, "1+1", cell.Output, "2", cell.Input, "x", cell.Output, "x")
+	new := newCells(cell.Input, "1+1", cell.Output, "3", cell.Input, "y", cell.Output, "x")
+	assert.Equal(t, []Change{
+		{Op: Modified, Old: 0, New: 0, Output: true},
+		{Op: Modified, Old: 2, New: 2, Input: true},
+	}, Diff(old, new))
+}
+
+func TestDiffMoved(t *testing.T) {
+	old := newCells(cell.Input, "a", cell.Output, "1", cell.Input, "b", cell.Input, "c")
+	new := newCells(cell.Input, "b", cell.Input, "c", cell.Input, "a", cell.Output, "2")
+	assert.Equal(t, []Change{
+		{Op: Equal, Old: 2, New: 0},
+		{Op: Equal, Old: 3, New: 1},
+		{Op: Moved, Old: 0, New: 2, Output: true},
+	}, Diff(old, new))
+	lines := DiffLines(old, new, Diff(old, new))
+	assert.Equal(t, []DiffLine{
+		{Equal, "Input: b"},
+		{Equal, "Input: c"},
+		{Moved, "Input: a (moved from cell 1 to 3)"},
+		{Removed, "Output: 1"},
+		{Added, "Output: 2"},
+	}, lines)
+}
@@ -0,0 +1,242 @@
+package nbx
+
+import (
+	"errors"
+	"fmt"
+	"github.com/corywalker/expreduce/expreduce/atoms"
+	"github.com/corywalker/expreduce/pkg/expreduceapi"
+	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/kernel"
+	"github.com/wrnrlr/foxtrot/theme"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Style names of a Cell[content, style, options...] expression,
+// the Mathematica names are accepted when reading as well.
+var cellStyles = map[string]cell.Type{
+	"Header1":       cell.H1,
+	"Header2":       cell.H2,
+	"Header3":       cell.H3,
+	"Header4":       cell.H4,
+	"Header5":       cell.H5,
+	"Header6":       cell.H6,
+	"Paragraph":     cell.Paragraph,
+	"Input":         cell.Input,
+	"Output":        cell.Output,
+	"Code":          cell.Code,
+	"Message":       cell.Output,
+	"Title":         cell.H1,
+	"Section":       cell.H2,
+	"Subsection":    cell.H3,
+	"Subsubsection": cell.H4,
+	"Text":          cell.Paragraph,
+}
+
+var promptRegexp = regexp.MustCompile(`\[(\d+)\]`)
+
+// WriteCellFile writes cells to filename as a Notebook[...] expression.
+func WriteCellFile(filename string, cells cell.Cells) error {
+	return writeAtomic(filename, func(w io.Writer) error {
+		return WriteCells(w, cells)
+	})
+}
+
+// WriteCells writes cells as a Notebook[Cell[...], ...] expression with one cell per line.
+func WriteCells(w io.Writer, cells cell.Cells) error {
+	var b strings.Builder
+	b.WriteString("Notebook[\n")
+	for i, c := range cells {
+		b.WriteString("    ")
+		b.WriteString(kernel.Format(CellToEx(c), "InputForm"))
+		if i < len(cells)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ToEx converts cells into a Notebook[Cell[...], ...] expression.
+func ToEx(cells cell.Cells) *atoms.Expression {
+	nb := atoms.E(global("Notebook"))
+	for _, c := range cells {
+		nb.AppendEx(CellToEx(c))
+	}
+	return nb
+}
+
+// FromEx converts a Notebook[Cell[...], ...] expression into cells, it accepts the short heads like Input[...] as well.
+func FromEx(ex expreduceapi.Ex) (cell.Cells, error) {
+	e, ok := ex.(*atoms.Expression)
+	if !ok || headName(e) != "Notebook" {
+		return nil, errors.New("not a Notebook[...] expression")
+	}
+	var cells cell.Cells
+	for _, part := range e.Parts[1:] {
+		c, err := parseCell(part)
+		if err != nil {
+			return nil, err
+		}
+		cells = append(cells, c)
+	}
+	return cells, nil
+}
+
+// CellToEx converts c into a Cell[content, style, CellLabel -> label] expression.
+// Input and output are wrapped in BoxData, an output cell with only an error becomes a "Message" cell.
+func CellToEx(c cell.Cell) *atoms.Expression {
+	var content expreduceapi.Ex
+	style := styleName(c.Type())
+	switch c.Type() {
+	case cell.Input, cell.Code:
+		content = atoms.E(global("BoxData"), atoms.NewString(c.Text()))
+	case cell.Output:
+		if c.Out() == nil && c.Err() != nil {
+			content = atoms.NewString(c.Err().Error())
+			style = "Message"
+			break
+		}
+		txt := c.Text()
+		if c.Out() != nil {
+			txt = kernel.Format(c.Out(), "InputForm")
+		}
+		content = atoms.E(global("BoxData"), atoms.NewString(txt), atoms.S("StandardForm"))
+	default:
+		content = atoms.NewString(c.Text())
+	}
+	ex := atoms.E(global("Cell"), content, atoms.NewString(style))
+	if c.Prompt() > 0 {
+		ex.AppendEx(atoms.E(atoms.S("Rule"), global("CellLabel"), atoms.NewString(c.Label())))
+	}
+	return ex
+}
+
+func styleName(t cell.Type) string {
+	switch t {
+	case cell.H1, cell.H2, cell.H3, cell.H4, cell.H5, cell.H6:
+		return fmt.Sprintf("Header%d", t-cell.H1+1)
+	case cell.Input, cell.Output, cell.Code:
+		return t.String()
+	default:
+		return "Paragraph"
+	}
+}
+
+// parseFullCell reads a Cell[content, style, options...] expression.
+func parseFullCell(e *atoms.Expression) (cell.Cell, error) {
+	if e.Len() < 2 {
+		return nil, errors.New("Cell needs a content and a style")
+	}
+	style, ok := e.GetPart(2).(*atoms.String)
+	if !ok {
+		return nil, errors.New("Cell style is not a String")
+	}
+	t, ok := cellStyles[style.Val]
+	if !ok {
+		return nil, fmt.Errorf("unknown cell style %q", style.Val)
+	}
+	content, err := parseBoxes(e.GetPart(1))
+	if err != nil {
+		return nil, err
+	}
+	label := ParseCellLabel(t)
+	for _, opt := range e.Parts[3:] {
+		if name, value, ok := parseOption(opt); ok && name == "CellLabel" {
+			label = value
+		}
+	}
+	c := cell.NewCell(t, label, theme.DefaultStyles())
+	c.SetPrompt(parsePrompt(label))
+	if style.Val == "Message" {
+		c.SetErr(errors.New(content))
+		return c, nil
+	}
+	c.SetText(content)
+	if t == cell.Output {
+		err = parseOutput(c, content)
+	}
+	return c, err
+}
+
+// parseBoxes turns a String, BoxData[...], RowBox[{...}] or List of boxes into text.
+func parseBoxes(ex expreduceapi.Ex) (string, error) {
+	switch e := ex.(type) {
+	case *atoms.String:
+		return e.Val, nil
+	case *atoms.Expression:
+		switch headName(e) {
+		case "BoxData", "RowBox":
+			if e.Len() < 1 {
+				return "", nil
+			}
+			return parseBoxes(e.GetPart(1))
+		case "List":
+			var b strings.Builder
+			for _, part := range e.Parts[1:] {
+				s, err := parseBoxes(part)
+				if err != nil {
+					return "", err
+				}
+				b.WriteString(s)
+			}
+			return b.String(), nil
+		}
+	}
+	return "", errors.New("cell content is not a String or BoxData")
+}
+
+// parseOption returns the name and the value of a Rule[name, "value"].
+func parseOption(ex expreduceapi.Ex) (string, string, bool) {
+	e, ok := ex.(*atoms.Expression)
+	if !ok || headName(e) != "Rule" || e.Len() != 2 {
+		return "", "", false
+	}
+	sym, ok := e.GetPart(1).(*atoms.Symbol)
+	if !ok {
+		return "", "", false
+	}
+	value, ok := e.GetPart(2).(*atoms.String)
+	if !ok {
+		return "", "", false
+	}
+	return shortName(sym.Name), value.Val, true
+}
+
+func parseOutput(c cell.Cell, content string) error {
+	if strings.TrimSpace(content) == "" {
+		return nil
+	}
+	ex, err := kernel.Parse(content)
+	if err != nil {
+		return err
+	}
+	c.SetOut(ex)
+	return nil
+}
+
+func parsePrompt(label string) int {
+	m := promptRegexp.FindStringSubmatch(label)
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n
+}
+
+func global(name string) expreduceapi.Ex {
+	return atoms.NewSymbol("Global`" + name)
+}
+
+// headName returns the head of e without its context.
+func headName(e *atoms.Expression) string {
+	return shortName(e.HeadStr())
+}
+
+func shortName(name string) string {
+	return name[strings.LastIndex(name, "`")+1:]
+}
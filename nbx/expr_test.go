@@ -0,0 +1,111 @@
+package nbx
+
+import (
+	"bytes"
+	"errors"
+	"github.com/corywalker/expreduce/expreduce/atoms"
+	"github.com/stretchr/testify/assert"
+	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/kernel"
+	"strings"
+	"testing"
+)
+
+func TestWriteCells(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	h := cell.NewCell(cell.H2, "", nil)
+	h.SetText("Arithmetic")
+	in := cell.NewCell(cell.Input, "In[1]:= ", nil)
+	in.SetText("a+1")
+	in.SetPrompt(1)
+	out := cell.NewCell(cell.Output, "Out[1]= ", nil)
+	out.SetPrompt(1)
+	ex, err := kernel.Parse("1 + a")
+	assert.Nil(t, err)
+	out.SetOut(ex)
+	failed := cell.NewCell(cell.Output, "Out[2]= ", nil)
+	failed.SetPrompt(2)
+	failed.SetErr(errors.New("Syntax::sntxf"))
+	err = WriteCells(buffer, cell.Cells{h, in, out, failed})
+	assert.Nil(t, err)
+	assert.Contains(t, buffer.String(), `Cell[BoxData["a+1"], "Input", CellLabel -> "In[1]:= "]`)
+	cells, err := readCells(buffer, "test.cell")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(cells))
+	assert.Equal(t, cell.H2, cells[0].Type())
+	assert.Equal(t, "Arithmetic", cells[0].Text())
+	assert.Equal(t, "a+1", cells[1].Text())
+	assert.Equal(t, 1, cells[1].Prompt())
+	assert.Equal(t, "In[1]:= ", cells[1].Label())
+	assert.Equal(t, "Plus[1, a]", kernel.Format(cells[2].Out(), "FullForm"))
+	assert.Equal(t, cell.Output, cells[3].Type())
+	assert.Equal(t, "Syntax::sntxf", cells[3].Err().Error())
+}
+
+func TestReadCells(t *testing.T) {
+	r := strings.NewReader(`Notebook[
+		Header1["Foxtrot"],
+		Cell["Hello", "Section"],
+		Cell[BoxData[RowBox[{"a", "+", "1"}]], "Input", CellLabel -> "In[3]:= "],
+		Cell[BoxData[RowBox[{"1", "+", "a"}], StandardForm], "Output", CellLabel -> "Out[3]= "],
+		Output["2"]
+	]`)
+	cells, err := readCells(r, "test.cell")
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(cells))
+	assert.Equal(t, cell.H1, cells[0].Type())
+	assert.Equal(t, cell.H2, cells[1].Type())
+	assert.Equal(t, "Hello", cells[1].Text())
+	assert.Equal(t, cell.Input, cells[2].Type())
+	assert.Equal(t, "a+1", cells[2].Text())
+	assert.Equal(t, 3, cells[2].Prompt())
+	assert.Equal(t, "Plus[1, a]", kernel.Format(cells[3].Out(), "FullForm"))
+	assert.Equal(t, "2", kernel.Format(cells[4].Out(), "FullForm"))
+	assert.Equal(t, 4, NextPrompt(cells))
+}
+
+func TestWriteCellsEscapesStrings(t *testing.T) {
+	in := cell.NewCell(cell.Input, "In[1]:= ", nil)
+	in.SetText(`StringJoin["say \"hi\"", "C:\\temp"]`)
+	in.SetPrompt(1)
+	out := cell.NewCell(cell.Output, "Out[1]= ", nil)
+	out.SetPrompt(1)
+	ex, err := kernel.Parse(`{"say \"hi\"", "C:\\temp", "two\nlines"}`)
+	assert.Nil(t, err)
+	out.SetOut(ex)
+	buffer := new(bytes.Buffer)
+	err = WriteCells(buffer, cell.Cells{in, out})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, strings.Count(buffer.String(), "\n"))
+	cells, err := readCells(buffer, "test.cell")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(cells))
+	assert.Equal(t, in.Text(), cells[0].Text())
+	assert.NotNil(t, cells[1].Out())
+	assert.Equal(t, kernel.Format(ex, "FullForm"), kernel.Format(cells[1].Out(), "FullForm"))
+	list, ok := cells[1].Out().(*atoms.Expression)
+	assert.True(t, ok)
+	assert.Equal(t, "say \"hi\"", list.GetPart(1).(*atoms.String).Val)
+	assert.Equal(t, `C:\temp`, list.GetPart(2).(*atoms.String).Val)
+	assert.Equal(t, "two\nlines", list.GetPart(3).(*atoms.String).Val)
+}
+
+func TestFromEx(t *testing.T) {
+	h := cell.NewCell(cell.H1, "", nil)
+	h.SetText("Title")
+	in := cell.NewCell(cell.Input, "In[1]:= ", nil)
+	in.SetText("a+1")
+	in.SetPrompt(1)
+	cells, err := FromEx(ToEx(cell.Cells{h, in}))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(cells))
+	assert.Equal(t, cell.H1, cells[0].Type())
+	assert.Equal(t, "Title", cells[0].Text())
+	assert.Equal(t, "a+1", cells[1].Text())
+	assert.Equal(t, 1, cells[1].Prompt())
+
+	ex, err := kernel.Parse(`{Cell["a", "Paragraph"]}`)
+	assert.Nil(t, err)
+	_, err = FromEx(ex)
+	assert.NotNil(t, err)
+}
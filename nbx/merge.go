@@ -0,0 +1,159 @@
+package nbx
+
+import (
+	"github.com/wrnrlr/foxtrot/cell"
+)
+
+// Markers of the paragraph cells around a conflict in a merged notebook.
+const (
+	ConflictOurs   = "<<<<<<< ours"
+	ConflictSep    = "======="
+	ConflictTheirs = ">>>>>>> theirs"
+)
+
+// Merge combines the changes from base to ours and from base to theirs, the cells are kept in
+// the order of ours except for the cells that only moved in theirs. A cell that was changed
+// differently on both sides, or changed on one side and removed on the other, is a conflict:
+// both versions are kept between marker cells and counted in conflicts.
+// Outputs that were changed differently on both sides are removed, they are made again by evaluation.
+func Merge(base, ours, theirs cell.Cells) (merged cell.Cells, conflicts int) {
+	m := &merger{base: base, ours: ours, theirs: theirs}
+	return m.merge(), m.conflicts
+}
+
+type merger struct {
+	base, ours, theirs cell.Cells
+	conflicts          int
+}
+
+// block is a part of the merged notebook, base is the index of the cell in base it was merged from
+// and -1 for a new cell.
+type block struct {
+	base  int
+	cells cell.Cells
+}
+
+func (m *merger) merge() cell.Cells {
+	oursOf, baseOfOurs := alignment(Diff(m.base, m.ours))
+	theirsOf, baseOfTheirs := alignment(Diff(m.base, m.theirs))
+	// The cells of ours are merged in their order, cells that only moved in theirs are placed later.
+	var blocks []block
+	placed := map[int]bool{}
+	relocated := map[int]cell.Cells{}
+	for _, j := range heads(m.ours) {
+		b, ok := baseOfOurs[j]
+		if !ok {
+			blocks = append(blocks, block{-1, unit(m.ours, j)})
+			continue
+		}
+		oc := oursOf[b]
+		tc, ok := theirsOf[b]
+		if !ok {
+			if oc.Op == Modified && oc.Input {
+				blocks = append(blocks, block{b, m.conflict(unit(m.ours, j), nil)})
+				placed[b] = true
+			}
+			continue
+		}
+		cells := m.resolve(oc, tc)
+		if tc.Op == Moved && oc.Op != Moved {
+			relocated[b] = cells
+			continue
+		}
+		blocks = append(blocks, block{b, cells})
+		placed[b] = true
+	}
+	for b, tc := range theirsOf {
+		if _, ok := oursOf[b]; !ok && tc.Op == Modified && tc.Input {
+			relocated[b] = m.conflict(nil, unit(m.theirs, tc.New))
+		}
+	}
+	// The new and relocated cells of theirs follow the cell of base that comes before them in theirs.
+	after := map[int][]cell.Cells{}
+	anchor := -1
+	for _, j := range heads(m.theirs) {
+		b, ok := baseOfTheirs[j]
+		switch {
+		case !ok && !m.addedInOurs(j, baseOfOurs):
+			after[anchor] = append(after[anchor], unit(m.theirs, j))
+		case ok && relocated[b] != nil:
+			after[anchor] = append(after[anchor], relocated[b])
+		case ok && placed[b]:
+			anchor = b
+		}
+	}
+	var merged cell.Cells
+	anchor = -1
+	for _, bl := range blocks {
+		if bl.base != -1 {
+			for _, cells := range after[anchor] {
+				merged = append(merged, cells...)
+			}
+			anchor = bl.base
+		}
+		merged = append(merged, bl.cells...)
+	}
+	for _, cells := range after[anchor] {
+		merged = append(merged, cells...)
+	}
+	return merged
+}
+
+// alignment maps the cells of base to their changes and the cells of the other version to base.
+func alignment(changes []Change) (changeOf map[int]Change, baseOf map[int]int) {
+	changeOf, baseOf = map[int]Change{}, map[int]int{}
+	for _, c := range changes {
+		if c.Old != -1 && c.New != -1 {
+			changeOf[c.Old] = c
+			baseOf[c.New] = c.Old
+		}
+	}
+	return changeOf, baseOf
+}
+
+// resolve merges a cell of base that is in both ours and theirs.
+func (m *merger) resolve(oc, tc Change) cell.Cells {
+	oi, ti := oc.Op == Modified && oc.Input, tc.Op == Modified && tc.Input
+	switch {
+	case oi && ti && !sameKey(m.ours, m.theirs, oc.New, tc.New):
+		return m.conflict(unit(m.ours, oc.New), unit(m.theirs, tc.New))
+	case ti && !oi:
+		return unit(m.theirs, tc.New)
+	case oi && !ti:
+		return unit(m.ours, oc.New)
+	}
+	head := m.ours[oc.New]
+	switch {
+	case tc.Output && !oc.Output:
+		return append(cell.Cells{head}, Outputs(m.theirs, tc.New)...)
+	case oc.Output && tc.Output && !sameOutputs(m.ours, m.theirs, oc.New, tc.New):
+		return cell.Cells{head}
+	}
+	return unit(m.ours, oc.New)
+}
+
+// addedInOurs reports whether the new cell at index j of theirs was added in ours as well.
+func (m *merger) addedInOurs(j int, baseOfOurs map[int]int) bool {
+	for _, i := range heads(m.ours) {
+		if _, ok := baseOfOurs[i]; !ok && sameKey(m.ours, m.theirs, i, j) {
+			return true
+		}
+	}
+	return false
+}
+
+// conflict keeps both versions of a cell between marker cells.
+func (m *merger) conflict(ours, theirs cell.Cells) cell.Cells {
+	m.conflicts++
+	cells := cell.Cells{marker(ConflictOurs)}
+	cells = append(cells, ours...)
+	cells = append(cells, marker(ConflictSep))
+	cells = append(cells, theirs...)
+	return append(cells, marker(ConflictTheirs))
+}
+
+func marker(text string) cell.Cell {
+	c := cell.NewCell(cell.Paragraph, "", nil)
+	c.SetText(text)
+	return c
+}
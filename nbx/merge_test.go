@@ -0,0 +1,46 @@
+package nbx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wrnrlr/foxtrot/cell"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	base := newCells(cell.Input, "a", cell.Input, "b", cell.Input, "c")
+	ours := newCells(cell.Input, "a", cell.Input, "b2", cell.Input, "c", cell.Input, "d")
+	theirs := newCells(cell.Input, "x", cell.Input, "a", cell.Input, "b", cell.Input, "c", cell.Output, "3")
+	merged, conflicts := Merge(base, ours, theirs)
+	assert.Equal(t, 0, conflicts)
+	assert.Equal(t, []string{"x", "a", "b2", "c", "3", "d"}, contents(merged))
+}
+
+func TestMergeMovedAndRemoved(t *testing.T) {
+	base := newCells(cell.Input, "a", cell.Input, "b", cell.Input, "c")
+	ours := newCells(cell.Input, "a2", cell.Input, "b", cell.Input, "c")
+	theirs := newCells(cell.Input, "b", cell.Input, "a", cell.Input, "d")
+	merged, conflicts := Merge(base, ours, theirs)
+	assert.Equal(t, 0, conflicts)
+	assert.Equal(t, []string{"b", "a2", "d"}, contents(merged))
+}
+
+func TestMergeConflict(t *testing.T) {
+	base := newCells(cell.Input, "a", cell.Input, "b")
+	ours := newCells(cell.Input, "a1", cell.Input, "b1")
+	theirs := newCells(cell.Input, "a2")
+	merged, conflicts := Merge(base, ours, theirs)
+	assert.Equal(t, 2, conflicts)
+	assert.Equal(t, []string{
+		ConflictOurs, "a1", ConflictSep, "a2", ConflictTheirs,
+		ConflictOurs, "b1", ConflictSep, ConflictTheirs,
+	}, contents(merged))
+}
+
+func TestMergeOutputs(t *testing.T) {
+	base := newCells(cell.Input, "a", cell.Output, "1", cell.Input, "b", cell.Output, "1")
+	ours := newCells(cell.Input, "a", cell.Output, "2", cell.Input, "b", cell.Output, "2")
+	theirs := newCells(cell.Input, "a", cell.Output, "1", cell.Input, "b", cell.Output, "3")
+	merged, conflicts := Merge(base, ours, theirs)
+	assert.Equal(t, 0, conflicts)
+	assert.Equal(t, []string{"a", "2", "b"}, contents(merged))
+}
@@ -0,0 +1,85 @@
+package nbx
+
+import (
+	"fmt"
+	"github.com/wrnrlr/foxtrot/cell"
+	"strconv"
+	"strings"
+)
+
+// Version of the .nbx format that is written, files with a newer version are refused by Read.
+const Version = "0.2.0"
+
+// Notebook is the content of a notebook file.
+type Notebook struct {
+	Cells cell.Cells
+	// PromptCount is the number of the next In[n] label.
+	PromptCount int
+}
+
+// NextPrompt returns the prompt count that follows the highest prompt number in cells.
+func NextPrompt(cells cell.Cells) int {
+	n := 0
+	for _, c := range cells {
+		if c.Prompt() > n {
+			n = c.Prompt()
+		}
+	}
+	return n + 1
+}
+
+func checkVersion(v string) error {
+	if v == "" {
+		return nil
+	}
+	cmp, err := compareVersions(v, Version)
+	if err != nil {
+		return err
+	}
+	if cmp > 0 {
+		return fmt.Errorf("nbx version %s is newer than the supported version %s", v, Version)
+	}
+	return nil
+}
+
+func compareVersions(a, b string) (int, error) {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := 0, 0
+		var err error
+		if i < len(as) {
+			if x, err = strconv.Atoi(as[i]); err != nil {
+				return 0, fmt.Errorf("invalid nbx version %q", a)
+			}
+		}
+		if i < len(bs) {
+			if y, err = strconv.Atoi(bs[i]); err != nil {
+				return 0, fmt.Errorf("invalid nbx version %q", b)
+			}
+		}
+		if x != y {
+			if x < y {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// Strip removes the output cells of a notebook and resets the prompts of its input cells,
+// notebooks with the same input cells are stored the same way after they are stripped.
+func Strip(nb *Notebook) *Notebook {
+	var cells cell.Cells
+	for _, c := range nb.Cells {
+		switch c.Type() {
+		case cell.Output:
+			continue
+		case cell.Input:
+			c.SetPrompt(0)
+			c.SetLabel(ParseCellLabel(cell.Input))
+		}
+		cells = append(cells, c)
+	}
+	return &Notebook{Cells: cells, PromptCount: 1}
+}
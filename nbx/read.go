@@ -10,22 +10,33 @@ import (
 	"github.com/corywalker/expreduce/expreduce/parser"
 	"github.com/corywalker/expreduce/pkg/expreduceapi"
 	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/ipynb"
+	"github.com/wrnrlr/foxtrot/kernel"
 	"github.com/wrnrlr/foxtrot/theme"
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 )
 
 type notebookTag struct {
 	XMLName xml.Name
 	Version string    `xml:"version,attr"`
+	Prompt  int       `xml:"prompt,attr,omitempty"`
 	Cells   []cellTag `xml:"cell"`
 }
 
 type cellTag struct {
 	XMLName xml.Name
 	Type    string `xml:"type,attr"`
+	// ID of an input cell, Input refers from an output cell to the ID of its input cell.
+	ID      int    `xml:"id,attr,omitempty"`
+	Input   int    `xml:"input,attr,omitempty"`
+	Prompt  int    `xml:"prompt,attr,omitempty"`
+	Label   string `xml:"label,attr,omitempty"`
+	Error   string `xml:"error,attr,omitempty"`
 	Content string `xml:",chardata"`
 }
 
@@ -34,6 +45,49 @@ type cll struct {
 	Children string
 }
 
+// ReadFile reads a .nbx file, a Jupyter .ipynb file or a Notebook[...] expression file
+// depending on the extension of filename.
+func ReadFile(filename string) (*Notebook, error) {
+	return ReadFileAs(filename, filename)
+}
+
+// ReadFileAs reads filename in the format of the extension of name,
+// like the temporary files without an extension that git passes to a merge driver.
+func ReadFileAs(filename, name string) (*Notebook, error) {
+	var cells cell.Cells
+	var err error
+	switch filepath.Ext(name) {
+	case ".nbx":
+		file, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return Decode(file)
+	case ".ipynb":
+		cells, err = ipynb.ReadFile(filename)
+	default:
+		cells, err = ReadCell(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Notebook{Cells: cells, PromptCount: NextPrompt(cells)}, nil
+}
+
+// ReadFrom reads a notebook from r, it is a .nbx notebook or a Notebook[...] expression
+// depending on the extension of filename.
+func ReadFrom(r io.Reader, filename string) (*Notebook, error) {
+	if filepath.Ext(filename) == ".nbx" {
+		return Decode(r)
+	}
+	cells, err := readCells(r, filename)
+	if err != nil {
+		return nil, err
+	}
+	return &Notebook{Cells: cells, PromptCount: NextPrompt(cells)}, nil
+}
+
 func ReadNBX(filename string) (cell.Cells, error) {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -80,7 +134,7 @@ func parseCells(ex expreduceapi.Ex) (cells cell.Cells, err error) {
 		return nil, errors.New("ex not an Expression")
 	}
 	// Check is List
-	if e.HeadStr() != "System`List" && e.HeadStr() != "Global`Notebook" {
+	if h := headName(e); h != "List" && h != "Notebook" {
 		return nil, errors.New("ex not an List")
 	}
 	// Loop over arguments
@@ -90,9 +144,6 @@ func parseCells(ex expreduceapi.Ex) (cells cell.Cells, err error) {
 			cells = append(cells, cell)
 		}
 	}
-	// Create Cell
-	// Add to cells
-	fmt.Println(ex)
 	return cells, err
 }
 
@@ -102,6 +153,9 @@ func parseCell(ex expreduceapi.Ex) (cell.Cell, error) {
 	if !ok {
 		return nil, errors.New("ex not an Expression")
 	}
+	if headName(e) == "Cell" {
+		return parseFullCell(e)
+	}
 	t, ok := ParseCellType(e)
 	if !ok {
 		return nil, errors.New("ex not an Expression")
@@ -116,6 +170,9 @@ func parseCell(ex expreduceapi.Ex) (cell.Cell, error) {
 	label := ParseCellLabel(t)
 	c := cell.NewCell(t, label, th)
 	c.SetText(txt)
+	if err == nil && t == cell.Output {
+		err = parseOutput(c, txt)
+	}
 	return c, err
 }
 
@@ -131,26 +188,26 @@ func ParseCellLabel(t cell.Type) string {
 }
 
 func ParseCellType(ex *atoms.Expression) (cell.Type, bool) {
-	switch ex.HeadStr() {
-	case "Global`Header1":
+	switch headName(ex) {
+	case "Header1":
 		return cell.H1, true
-	case "Global`Header2":
+	case "Header2":
 		return cell.H2, true
-	case "Global`Header3":
+	case "Header3":
 		return cell.H3, true
-	case "Global`Header4":
+	case "Header4":
 		return cell.H4, true
-	case "Global`Header5":
+	case "Header5":
 		return cell.H5, true
-	case "Global`Header6":
+	case "Header6":
 		return cell.H6, true
-	case "Global`Paragraph":
+	case "Paragraph":
 		return cell.Paragraph, true
-	case "Global`Input":
+	case "Input":
 		return cell.Input, true
-	case "Global`Output":
+	case "Output":
 		return cell.Output, true
-	case "Global`Code":
+	case "Code":
 		return cell.Code, true
 	default:
 		return cell.Empty, false
@@ -174,36 +231,73 @@ func ParseExpression(ex expreduceapi.Ex) (*atoms.Expression, bool) {
 }
 
 func Read(r io.Reader) (cell.Cells, error) {
-	var nb notebookTag
-	nb.XMLName = xml.Name{Local: "notebook"}
+	nb, err := Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return nb.Cells, nil
+}
+
+// Decode reads a notebook in the .nbx format.
+func Decode(r io.Reader) (*Notebook, error) {
+	var tag notebookTag
+	tag.XMLName = xml.Name{Local: "notebook"}
 	b, err := ioutil.ReadAll(r)
 	if err != nil {
 		fmt.Println("Failed to read file.")
 		return nil, err
 	}
-	err = xml.Unmarshal(b, &nb)
+	err = xml.Unmarshal(b, &tag)
 	if err != nil && err != io.EOF {
 		fmt.Println("Failed to parse file.")
 		return nil, err
 	}
-	fmt.Printf("%v\n", nb)
+	if err := checkVersion(tag.Version); err != nil {
+		return nil, err
+	}
 	styles := theme.DefaultStyles()
-	kernel := expreduce.NewEvalState()
-	var cells cell.Cells
-	for i, c := range nb.Cells {
-		c2 := cell.NewCell(cell.ParseType(c.Type), string(i), styles)
+	nb := &Notebook{PromptCount: tag.Prompt}
+	ids := map[int]bool{}
+	for _, c := range tag.Cells {
+		t := cell.ParseType(c.Type)
+		label := c.Label
+		if label == "" {
+			label = ParseCellLabel(t)
+		}
+		c2 := cell.NewCell(t, label, styles)
 		c2.SetText(c.Content)
-		if c2.Type() == cell.Output {
-			src := parser.ReplaceSyms(c.Content)
-			buf := bytes.NewBufferString(src)
-			expOut, err := parser.InterpBuf(buf, "nofile", kernel)
-			if err != nil {
+		c2.SetPrompt(c.Prompt)
+		if c.ID != 0 {
+			ids[c.ID] = true
+		}
+		if t == cell.Output {
+			if c.Input != 0 && !ids[c.Input] {
+				return nil, fmt.Errorf("output cell refers to unknown input cell %d", c.Input)
+			}
+			if err := decodeOutput(c2, c); err != nil {
 				fmt.Println("Failed to read output cell")
 				return nil, err
 			}
-			c2.SetOut(expOut)
 		}
-		cells = append(cells, c2)
+		nb.Cells = append(nb.Cells, c2)
 	}
-	return cells, nil
+	if nb.PromptCount == 0 {
+		nb.PromptCount = NextPrompt(nb.Cells)
+	}
+	return nb, nil
+}
+
+func decodeOutput(c cell.Cell, tag cellTag) error {
+	if tag.Error != "" {
+		c.SetErr(errors.New(tag.Error))
+	}
+	if strings.TrimSpace(tag.Content) == "" {
+		return nil
+	}
+	ex, err := kernel.Parse(tag.Content)
+	if err != nil {
+		return err
+	}
+	c.SetOut(ex)
+	return nil
 }
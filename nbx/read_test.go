@@ -3,6 +3,7 @@ package nbx
 import (
 	"github.com/stretchr/testify/assert"
 	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/kernel"
 	"strings"
 	"testing"
 )
@@ -35,3 +36,31 @@ func TestReadInvalidXML(t *testing.T) {
 	_, err := Read(r)
 	assert.NotNil(t, err)
 }
+
+func TestReadNewerVersion(t *testing.T) {
+	r := strings.NewReader(`<notebook version="99.0.0"></notebook>`)
+	_, err := Read(r)
+	assert.NotNil(t, err)
+}
+
+func TestReadUnknownInput(t *testing.T) {
+	r := strings.NewReader(`
+	<notebook version="0.2.0">
+		<cell type="Input" id="1">1+1</cell>
+		<cell type="Output" input="2">2</cell>
+	</notebook>`)
+	_, err := Read(r)
+	assert.NotNil(t, err)
+}
+
+func TestReadPromptCount(t *testing.T) {
+	r := strings.NewReader(`
+	<notebook version="0.2.0">
+		<cell type="Input" id="1" prompt="7">1+1</cell>
+		<cell type="Output" input="1" prompt="7">2</cell>
+	</notebook>`)
+	nb, err := Decode(r)
+	assert.Nil(t, err)
+	assert.Equal(t, 8, nb.PromptCount)
+	assert.Equal(t, "2", kernel.Format(nb.Cells[1].Out(), "FullForm"))
+}
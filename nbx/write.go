@@ -2,73 +2,79 @@ package nbx
 
 import (
 	"encoding/xml"
-	"github.com/corywalker/expreduce/expreduce/atoms"
-	"github.com/corywalker/expreduce/pkg/expreduceapi"
 	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/ipynb"
+	"github.com/wrnrlr/foxtrot/kernel"
 	"io"
-	"os"
+	"path/filepath"
 )
 
-const (
-	title       = `cell["\<Hello\>","H5"]`
-	aPlusOneIn  = `cell[BoxData[RowBox[{"a","+","1"}]],"Input",CellLabel -> "Content[1]:= "]`
-	aPlusOneOut = `cell[BoxData[RowBox[{"1","+","a"}],StandardForm],"Output",CellLabel -> "Out[1]= "]`
-	inAndOut    = `CompoundExpression[
-		cell[BoxData[RowBox[List["a", "+", "1"]]], "Input", Rule[CellLabel, "Content[1]:= "]],
-		cell[BoxData[RowBox[List["1", "+", "a"]], StandardForm], "Output", Rule[CellLabel, "Out[1]= "]]]
-`
-)
-
-const temp = `<cell type="%s">"%s"</cell>`
-
 // Write cells to filename.nbx
 func WriteFile(filename string, cells cell.Cells) error {
-	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0660)
-	defer file.Close()
-	if err != nil {
-		return err
+	return WriteNotebookFile(filename, &Notebook{Cells: cells, PromptCount: NextPrompt(cells)})
+}
+
+// Write a notebook to filename.nbx, a filename with the .ipynb extension is written as a Jupyter notebook
+// and one with the .cell extension as a Notebook[...] expression. The file is replaced at once when
+// the notebook was written completely.
+func WriteNotebookFile(filename string, nb *Notebook) error {
+	return WriteNotebookFileAs(filename, filename, nb)
+}
+
+// WriteNotebookFileAs writes a notebook to filename in the format of the extension of name.
+func WriteNotebookFileAs(filename, name string, nb *Notebook) error {
+	switch filepath.Ext(name) {
+	case ".ipynb":
+		return writeAtomic(filename, func(w io.Writer) error {
+			return ipynb.Write(w, nb.Cells)
+		})
+	case ".cell":
+		return WriteCellFile(filename, nb.Cells)
 	}
-	file.Truncate(0)
-	file.Seek(0, 0)
-	return Write(file, cells)
+	return writeAtomic(filename, func(w io.Writer) error {
+		return Encode(w, nb)
+	})
 }
 
 func Write(w io.Writer, cells cell.Cells) error {
-	nb := &notebookTag{}
-	nb.XMLName = xml.Name{Local: "notebook"}
-	for _, c := range cells {
-		cell := cellTag{}
-		cell.Type = c.Type().String()
-		cell.Content = c.Text()
-		cell.XMLName = xml.Name{Local: "cell"}
-		nb.Cells = append(nb.Cells, cell)
+	return Encode(w, &Notebook{Cells: cells, PromptCount: NextPrompt(cells)})
+}
+
+// Encode writes a notebook in the .nbx format, output cells are stored as FullForm expressions.
+func Encode(w io.Writer, nb *Notebook) error {
+	tag := &notebookTag{Version: Version, Prompt: nb.PromptCount}
+	tag.XMLName = xml.Name{Local: "notebook"}
+	input := 0
+	for i, c := range nb.Cells {
+		ct := cellTag{
+			Type:    c.Type().String(),
+			Prompt:  c.Prompt(),
+			Label:   c.Label(),
+			Content: c.Text()}
+		ct.XMLName = xml.Name{Local: "cell"}
+		switch c.Type() {
+		case cell.Input:
+			ct.ID = i + 1
+			input = ct.ID
+		case cell.Output:
+			ct.Input = input
+			if err := c.Err(); err != nil {
+				ct.Error = err.Error()
+			}
+			if ex := c.Out(); ex != nil {
+				ct.Content = kernel.Format(ex, "FullForm")
+			} else if c.Err() != nil {
+				ct.Content = ""
+			}
+		default:
+			input = 0
+		}
+		tag.Cells = append(tag.Cells, ct)
 	}
-	b, err := xml.MarshalIndent(nb, "", "  ")
+	b, err := xml.MarshalIndent(tag, "", "  ")
 	if err != nil {
 		return err
 	}
 	_, err = w.Write(b)
 	return err
 }
-
-func WriteCells(w io.Writer, cells cell.Cells) expreduceapi.Ex {
-	res := atoms.E(atoms.S("List"))
-
-	//keys := make([]streamKey, 0)
-	//for k := range sm.openStreams {
-	//	keys = append(keys, k)
-	//}
-	//sort.Slice(keys, func(i, j int) bool {
-	//	return keys[i].id < keys[j].id
-	//})
-	//
-	//for _, k := range keys {
-	//	res.AppendEx(atoms.E(
-	//		atoms.S("OutputStream"),
-	//		atoms.NewString(k.name),
-	//		atoms.NewInt(k.id),
-	//	))
-	//}
-
-	return res
-}
@@ -2,8 +2,10 @@ package nbx
 
 import (
 	"bytes"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/kernel"
 	"testing"
 )
 
@@ -17,3 +19,47 @@ func TestWrite(t *testing.T) {
 	cells, err = Read(buffer)
 	assert.Equal(t, 1, len(cells))
 }
+
+func TestWriteOutput(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	in := cell.NewCell(cell.Input, "In[3]:= ", nil)
+	in.SetText("1/2")
+	in.SetPrompt(3)
+	out := cell.NewCell(cell.Output, "Out[3]= ", nil)
+	out.SetPrompt(3)
+	ex, err := kernel.Parse("Rational[1, 2]")
+	assert.Nil(t, err)
+	out.SetOut(ex)
+	failed := cell.NewCell(cell.Output, "Out[4]= ", nil)
+	failed.SetErr(errors.New("Syntax::sntxf"))
+	nb := &Notebook{Cells: cell.Cells{in, out, failed}, PromptCount: 5}
+	err = Encode(buffer, nb)
+	assert.Nil(t, err)
+	nb, err = Decode(buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, nb.PromptCount)
+	assert.Equal(t, 3, len(nb.Cells))
+	assert.Equal(t, cell.Input, nb.Cells[0].Type())
+	assert.Equal(t, "In[3]:= ", nb.Cells[0].Label())
+	assert.Equal(t, 3, nb.Cells[1].Prompt())
+	assert.Equal(t, "Out[3]= ", nb.Cells[1].Label())
+	assert.Equal(t, "Rational[1, 2]", kernel.Format(nb.Cells[1].Out(), "FullForm"))
+	assert.Nil(t, nb.Cells[2].Out())
+	assert.Equal(t, "Syntax::sntxf", nb.Cells[2].Err().Error())
+}
+
+func TestStrip(t *testing.T) {
+	nb := &Notebook{Cells: newCells(cell.H1, "Title", cell.Input, "1/0", cell.Output, "Power::infy"), PromptCount: 4}
+	nb.Cells[1].SetPrompt(3)
+	nb.Cells[1].SetLabel("In[3]:= ")
+	stripped := Strip(nb)
+	assert.Equal(t, []string{"Title", "1/0"}, contents(stripped.Cells))
+	assert.Equal(t, 0, stripped.Cells[1].Prompt())
+	assert.Equal(t, 1, stripped.PromptCount)
+	first, second := new(bytes.Buffer), new(bytes.Buffer)
+	assert.Nil(t, Encode(first, stripped))
+	again, err := Decode(bytes.NewReader(first.Bytes()))
+	assert.Nil(t, err)
+	assert.Nil(t, Encode(second, Strip(again)))
+	assert.Equal(t, first.String(), second.String())
+}
@@ -0,0 +1,99 @@
+package notebook
+
+import (
+	"bytes"
+	"github.com/atotto/clipboard"
+	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/nbx"
+	"log"
+	"strings"
+)
+
+// CopySelected returns the selected cells in the .nbx format so they can be pasted in another notebook.
+func (nb *Notebook) CopySelected() (string, error) {
+	var cells cell.Cells
+	for i, c := range nb.Cells {
+		if nb.selection.IsSelected(i) {
+			cells = append(cells, c)
+		}
+	}
+	if len(cells) == 0 {
+		return "", nil
+	}
+	var b bytes.Buffer
+	if err := nbx.Write(&b, cells); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// CutSelected deletes the selected cells and returns them in the .nbx format.
+func (nb *Notebook) CutSelected() (string, error) {
+	s, err := nb.CopySelected()
+	if err != nil || s == "" {
+		return s, err
+	}
+	nb.DeleteSelected()
+	return s, nil
+}
+
+// Paste inserts the cells in s after the selection, or at the end of the notebook when nothing is selected.
+// Cells copied from a notebook are in the .nbx format, other text is pasted as Input cells
+// with one cell for every block of text separated by an empty line.
+func (nb *Notebook) Paste(s string) error {
+	cells, err := nb.pastedCells(s)
+	if err != nil || len(cells) == 0 {
+		return err
+	}
+	index := len(nb.Cells)
+	if nb.selection.first != -1 {
+		index = nb.selection.max() + 1
+		if index > len(nb.Cells) {
+			index = len(nb.Cells)
+		}
+	}
+	nb.selectRange(nb.apply(&insertCells{index, cells}))
+	return nil
+}
+
+func (nb *Notebook) pastedCells(s string) (cell.Cells, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "<notebook") {
+		return nbx.Read(strings.NewReader(s))
+	}
+	var cells cell.Cells
+	for _, block := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n\n") {
+		if block = strings.TrimSpace(block); block != "" {
+			c := nb.newCell(cell.Input)
+			c.SetText(block)
+			cells = append(cells, c)
+		}
+	}
+	return cells, nil
+}
+
+func (nb *Notebook) copyToClipboard(cut bool) {
+	var s string
+	var err error
+	if cut {
+		s, err = nb.CutSelected()
+	} else {
+		s, err = nb.CopySelected()
+	}
+	if err == nil && s != "" {
+		err = clipboard.WriteAll(s)
+	}
+	if err != nil {
+		log.Printf("failed to copy cells: %v", err)
+	}
+}
+
+func (nb *Notebook) pasteFromClipboard() {
+	s, err := clipboard.ReadAll()
+	if err == nil {
+		err = nb.Paste(s)
+	}
+	if err != nil {
+		log.Printf("failed to paste cells: %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package notebook
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/wrnrlr/foxtrot/cell"
+	"testing"
+)
+
+func TestCopyPasteCells(t *testing.T) {
+	src := newTestNotebook("a", "b", "c")
+	src.selection.SetFirst(0)
+	src.selection.SetLast(1)
+	s, err := src.CopySelected()
+	assert.Nil(t, err)
+	assert.Contains(t, s, "<notebook")
+	dst := newTestNotebook("x", "y")
+	dst.selection.SetFirst(0)
+	err = dst.Paste(s)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"x", "a", "b", "y"}, texts(dst))
+	assert.True(t, dst.selection.IsSelected(1))
+	assert.True(t, dst.selection.IsSelected(2))
+	assert.True(t, dst.Undo())
+	assert.Equal(t, []string{"x", "y"}, texts(dst))
+}
+
+func TestCutCells(t *testing.T) {
+	nb := newTestNotebook("a", "b", "c")
+	nb.selection.SetFirst(1)
+	s, err := nb.CutSelected()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "c"}, texts(nb))
+	err = nb.Paste(s)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "c", "b"}, texts(nb))
+}
+
+func TestPasteText(t *testing.T) {
+	nb := newTestNotebook()
+	err := nb.Paste("x = 1\ny = 2\n\nx + y\n")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"x = 1\ny = 2", "x + y"}, texts(nb))
+	assert.Equal(t, cell.Input, nb.Cells[1].Type())
+}
@@ -1,30 +1,264 @@
 package notebook
 
 import (
-	"bytes"
-	"fmt"
-	"github.com/corywalker/expreduce/expreduce/parser"
 	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/editor"
+	"github.com/wrnrlr/foxtrot/kernel"
+	"github.com/wrnrlr/foxtrot/nbx"
 )
 
+const runningLabel = "In[*]:= "
+
+// eval queues the cell for evaluation by the kernel, the output is inserted when the result arrives.
 func (nb *Notebook) eval(i int) {
 	c := nb.Cells[i]
 	textIn := c.Text()
-	if textIn == "" {
+	if textIn == "" || nb.isPending(c) {
+		return
+	}
+	nb.worker.SetNotebook(nbx.ToEx(nb.Cells))
+	nb.queue(c, nb.worker.Submit(textIn))
+	nb.focusSlot(i + 1)
+}
+
+func (nb *Notebook) queue(c cell.Cell, j *kernel.Job) {
+	c.SetLabel(runningLabel)
+	c.SetState(cell.Queued)
+	nb.jobs[j] = c
+}
+
+// batch is the evaluation of several cells one after the other, remaining are the jobs of the cells
+// that are not evaluated yet.
+type batch struct {
+	remaining []*kernel.Job
+	total     int
+}
+
+func (b *batch) remove(j *kernel.Job) {
+	for i, r := range b.remaining {
+		if r == j {
+			b.remaining = append(b.remaining[:i], b.remaining[i+1:]...)
+			break
+		}
+	}
+	if len(b.remaining) == 0 {
+		b.total = 0
+	}
+}
+
+// evalCells queues the input cells among cells in their order, the output of a cell replaces its
+// existing output cell. When the evaluation of a cell fails the cells after it are stopped.
+func (nb *Notebook) evalCells(cells cell.Cells) {
+	var queued cell.Cells
+	var srcs []string
+	for _, c := range cells {
+		if c.Type() == cell.Input && c.Text() != "" && !nb.isPending(c) {
+			queued = append(queued, c)
+			srcs = append(srcs, c.Text())
+		}
+	}
+	if len(srcs) == 0 {
+		return
+	}
+	nb.stopped = nil
+	nb.worker.SetNotebook(nbx.ToEx(nb.Cells))
+	for i, j := range nb.worker.SubmitBatch(srcs...) {
+		nb.queue(queued[i], j)
+		nb.batch.remaining = append(nb.batch.remaining, j)
+		nb.batch.total++
+	}
+}
+
+// EvalAll evaluates all input cells from top to bottom.
+func (nb *Notebook) EvalAll() {
+	nb.evalCells(nb.Cells)
+}
+
+// EvalAbove evaluates the input cells above the cursor.
+func (nb *Notebook) EvalAbove() {
+	if i := nb.cursor(); i > 0 {
+		nb.evalCells(nb.Cells[:i])
+	}
+}
+
+// EvalBelow evaluates the cell at the cursor and the input cells below it.
+func (nb *Notebook) EvalBelow() {
+	if i := nb.cursor(); i >= 0 && i < len(nb.Cells) {
+		nb.evalCells(nb.Cells[i:])
+	}
+}
+
+// EvalSelected evaluates the selected input cells.
+func (nb *Notebook) EvalSelected() {
+	var cells cell.Cells
+	for i, c := range nb.Cells {
+		if nb.selection.IsSelected(i) {
+			cells = append(cells, c)
+		}
+	}
+	nb.evalCells(cells)
+}
+
+// Progress is the number of cells of the current evaluation of several cells that are not evaluated yet
+// and the number of cells of the evaluation, both are zero when there is none.
+func (nb *Notebook) Progress() (remaining, total int) {
+	return len(nb.batch.remaining), nb.batch.total
+}
+
+// Stopped is the number of cells that were not evaluated because the evaluation of a cell before them failed.
+func (nb *Notebook) Stopped() int {
+	return len(nb.stopped)
+}
+
+// ContinueEval evaluates the cells that were stopped by a failed evaluation.
+func (nb *Notebook) ContinueEval() {
+	var cells cell.Cells
+	for _, c := range nb.stopped {
+		if nb.indexOf(c) != -1 {
+			cells = append(cells, c)
+		}
+	}
+	nb.evalCells(cells)
+	nb.stopped = nil
+}
+
+// DismissStopped forgets the cells that were stopped by a failed evaluation.
+func (nb *Notebook) DismissStopped() {
+	nb.stopped = nil
+}
+
+// cursor is the index of the focused cell or the cell after the focused slot, it is -1 when there is none.
+func (nb *Notebook) cursor() int {
+	for i, c := range nb.Cells {
+		if c.Focused() {
+			return i
+		}
+	}
+	if nb.activeSlot >= 0 {
+		return nb.activeSlot
+	}
+	if nb.selection.first != -1 {
+		return nb.selection.min()
+	}
+	return -1
+}
+
+// stopCancelled moves the cells whose jobs the worker cancelled after a failed evaluation to stopped.
+func (nb *Notebook) stopCancelled() {
+	for _, j := range append([]*kernel.Job{}, nb.batch.remaining...) {
+		if nb.worker.State(j) != kernel.Cancelled {
+			continue
+		}
+		nb.batch.remove(j)
+		if c, ok := nb.jobs[j]; ok {
+			delete(nb.jobs, j)
+			c.SetState(cell.Idle)
+			c.SetLabel("")
+			nb.stopped = append(nb.stopped, c)
+		}
+	}
+}
+
+// abort cancels the cell when it is waiting in the queue, otherwise the running evaluation is aborted.
+func (nb *Notebook) abort(i int) {
+	c := nb.Cells[i]
+	for j, jc := range nb.jobs {
+		if jc == c && nb.worker.Cancel(j) {
+			delete(nb.jobs, j)
+			nb.batch.remove(j)
+			c.SetState(cell.Idle)
+			c.SetLabel("")
+			return
+		}
+	}
+	nb.worker.Abort()
+}
+
+func (nb *Notebook) isPending(c cell.Cell) bool {
+	for _, jc := range nb.jobs {
+		if jc == c {
+			return true
+		}
+	}
+	return false
+}
+
+// KernelEvents applies the results of the kernel and marks the cells that are running,
+// Event calls it for a notebook that is laid out.
+func (nb *Notebook) KernelEvents() {
+	for _, r := range nb.worker.Results() {
+		nb.setResult(r)
+	}
+	nb.stopCancelled()
+	for j, c := range nb.jobs {
+		if nb.worker.State(j) == kernel.Running {
+			c.SetState(cell.Running)
+		}
+	}
+}
+
+func (nb *Notebook) setResult(r kernel.Result) {
+	c, ok := nb.jobs[r.Job]
+	if !ok {
+		return
+	}
+	delete(nb.jobs, r.Job)
+	nb.batch.remove(r.Job)
+	c.SetState(cell.Idle)
+	i := nb.indexOf(c)
+	if i == -1 {
 		return
 	}
-	c.SetLabel(fmt.Sprintf("Content[%d]:= ", nb.promptCount))
-	src := parser.ReplaceSyms(textIn)
-	buf := bytes.NewBufferString(src)
-	expOut, err := parser.InterpBuf(buf, "nofile", nb.kernel)
-	expOut = nb.kernel.Eval(expOut)
+	c.SetLabel(kernel.InLabel(r.Prompt))
+	c.SetPrompt(r.Prompt)
+	if r.Notebook != nil {
+		cells, err := nbx.FromEx(r.Notebook)
+		if err == nil {
+			nb.apply(&replaceCells{append(cell.Cells{}, nb.Cells...), cells})
+			return
+		}
+		r.Ex, r.Err = nil, err
+	}
+	out := nb.newCell(cell.Output)
+	out.SetOut(r.Ex)
+	out.SetErr(r.Err)
+	out.SetLabel(kernel.OutLabel(r.Prompt))
+	out.SetPrompt(r.Prompt)
+	var old cell.Cell
 	if nb.isOutputCell(i + 1) {
-		nb.DeleteCell(i + i)
+		old = nb.Cells[i+1]
+	}
+	nb.apply(&replaceOutput{i + 1, old, out})
+}
+
+// complete shows the symbols of the kernel that start with prefix in the editor of c.
+func (nb *Notebook) complete(c cell.Cell, prefix string) {
+	var items []editor.Completion
+	for _, s := range nb.kernel.Symbols(prefix) {
+		items = append(items, editor.Completion{Name: s.Name, Usage: s.Usage, Function: s.Function})
+	}
+	c.ShowCompletions(items)
+}
+
+func (nb *Notebook) indexOf(c cell.Cell) int {
+	for i, c2 := range nb.Cells {
+		if c2 == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// Updated receives a value when the kernel has made progress and the notebook should be redrawn,
+// it is closed once the notebook is closed and its running cell has finished.
+func (nb *Notebook) Updated() <-chan struct{} {
+	return nb.worker.Updated()
+}
+
+func (nb *Notebook) abortSelected() {
+	for i := range nb.Cells {
+		if nb.selection.IsSelected(i) && nb.isPending(nb.Cells[i]) {
+			nb.abort(i)
+		}
 	}
-	nb.InsertCell(i+1, cell.Output)
-	nb.Cells[i+1].SetOut(expOut)
-	nb.Cells[i+1].SetErr(err)
-	nb.Cells[i+1].SetLabel(fmt.Sprintf("Out[%d]= ", nb.promptCount))
-	nb.promptCount++
-	nb.focusSlot(i + 1)
 }
@@ -5,19 +5,26 @@ import (
 	. "github.com/wrnrlr/foxtrot/cell"
 )
 
+// Event handles the events of the cells, slots and selection,
+// it returns the events that concern the notebook as a whole, like SaveNotebookEvent.
 func (nb *Notebook) Event(gtx *Context) interface{} {
-	nb.cellEvents(gtx)
+	e := nb.cellEvents(gtx)
 	nb.slotEvents(gtx)
-	nb.selectionEvent(gtx)
-	return nil
+	if se := nb.selectionEvent(gtx); se != nil {
+		e = se
+	}
+	nb.KernelEvents()
+	return e
 }
 
-func (nb *Notebook) cellEvents(gtx *Context) {
+func (nb *Notebook) cellEvents(gtx *Context) (result interface{}) {
 	for i, c := range nb.Cells {
 		e := c.Event(gtx)
 		switch e := e.(type) {
 		case EvalEvent:
 			nb.eval(i)
+		case AbortEvent:
+			nb.abort(i)
 		case SelectFirstCellEvent:
 			nb.unfocusSlot()
 			nb.selection.SetFirst(i)
@@ -26,8 +33,13 @@ func (nb *Notebook) cellEvents(gtx *Context) {
 			nb.selection.SetLast(i)
 		case FocusPlaceholder:
 			nb.focusSlot(i + e.Offset)
+		case CompleteEvent:
+			nb.complete(c, e.Prefix)
+		case SaveEvent:
+			result = SaveNotebookEvent{As: e.As}
 		}
 	}
+	return result
 }
 
 func (nb *Notebook) slotEvents(gtx *Context) {
@@ -62,14 +74,33 @@ func (nb *Notebook) slotEvents(gtx *Context) {
 	}
 }
 
-func (nb *Notebook) selectionEvent(gtx *Context) {
+func (nb *Notebook) selectionEvent(gtx *Context) (result interface{}) {
 	es := nb.selection.Event(gtx)
 	for _, e := range es {
 		switch e := e.(type) {
 		case DeleteSelected:
 			nb.DeleteSelected()
+		case AbortSelected:
+			nb.abortSelected()
+		case CopySelected:
+			nb.copyToClipboard(false)
+		case CutSelected:
+			nb.copyToClipboard(true)
+		case PasteEvent:
+			nb.pasteFromClipboard()
+		case MoveSelected:
+			nb.moveSelected(e.Delta)
+		case SetTypeSelected:
+			nb.setTypeSelected(e.Type)
+		case UndoEvent:
+			nb.Undo()
+		case RedoEvent:
+			nb.Redo()
 		case FocusSlotEvent:
 			nb.focusSlot(e.Index)
+		case SaveNotebookEvent:
+			result = e
 		}
 	}
+	return result
 }
@@ -0,0 +1,198 @@
+package notebook
+
+import "github.com/wrnrlr/foxtrot/cell"
+
+// A command is a structural change to the cells of a notebook that can be undone.
+// Both do and undo return the range of cells that was changed, first is -1 when no cells remain to select.
+type command interface {
+	do(nb *Notebook) (first, last int)
+	undo(nb *Notebook) (first, last int)
+}
+
+// History keeps the commands that were applied to a notebook.
+type History struct {
+	undo, redo []command
+}
+
+// insertCells adds cells at index.
+type insertCells struct {
+	index int
+	cells cell.Cells
+}
+
+func (c *insertCells) do(nb *Notebook) (int, int) {
+	nb.insertCells(c.index, c.cells...)
+	return c.index, c.index + len(c.cells) - 1
+}
+
+func (c *insertCells) undo(nb *Notebook) (int, int) {
+	nb.removeCells(c.index, len(c.cells))
+	return -1, -1
+}
+
+// deleteCells removes a range of cells, the cells are kept to restore them.
+type deleteCells struct {
+	index int
+	cells cell.Cells
+}
+
+func (c *deleteCells) do(nb *Notebook) (int, int) {
+	nb.removeCells(c.index, len(c.cells))
+	return -1, -1
+}
+
+func (c *deleteCells) undo(nb *Notebook) (int, int) {
+	nb.insertCells(c.index, c.cells...)
+	return c.index, c.index + len(c.cells) - 1
+}
+
+// moveCell moves the cell at index from to index to.
+type moveCell struct {
+	from, to int
+}
+
+func (c *moveCell) do(nb *Notebook) (int, int) {
+	nb.insertCells(c.to, nb.removeCells(c.from, 1)...)
+	return c.to, c.to
+}
+
+func (c *moveCell) undo(nb *Notebook) (int, int) {
+	nb.insertCells(c.from, nb.removeCells(c.to, 1)...)
+	return c.from, c.from
+}
+
+// setType changes the type of a cell.
+type setType struct {
+	index    int
+	from, to cell.Type
+}
+
+func (c *setType) do(nb *Notebook) (int, int) {
+	nb.Cells[c.index].SetType(c.to)
+	return c.index, c.index
+}
+
+func (c *setType) undo(nb *Notebook) (int, int) {
+	nb.Cells[c.index].SetType(c.from)
+	return c.index, c.index
+}
+
+// replaceOutput puts the output of an evaluation at index, old is the previous output cell if there was one.
+type replaceOutput struct {
+	index    int
+	old, new cell.Cell
+}
+
+func (c *replaceOutput) do(nb *Notebook) (int, int) {
+	if c.old != nil {
+		nb.removeCells(c.index, 1)
+	}
+	nb.insertCells(c.index, c.new)
+	return c.index, c.index
+}
+
+func (c *replaceOutput) undo(nb *Notebook) (int, int) {
+	nb.removeCells(c.index, 1)
+	if c.old == nil {
+		return -1, -1
+	}
+	nb.insertCells(c.index, c.old)
+	return c.index, c.index
+}
+
+// clearOutputs removes the output cells at indexes, the cells are kept to restore them.
+type clearOutputs struct {
+	indexes []int
+	cells   cell.Cells
+}
+
+func (c *clearOutputs) do(nb *Notebook) (int, int) {
+	for i := len(c.indexes) - 1; i >= 0; i-- {
+		nb.removeCells(c.indexes[i], 1)
+	}
+	return -1, -1
+}
+
+func (c *clearOutputs) undo(nb *Notebook) (int, int) {
+	for i, index := range c.indexes {
+		nb.insertCells(index, c.cells[i])
+	}
+	return -1, -1
+}
+
+// replaceCells replaces all cells of the notebook, like NotebookPut does.
+type replaceCells struct {
+	old, new cell.Cells
+}
+
+func (c *replaceCells) do(nb *Notebook) (int, int) {
+	nb.removeCells(0, len(c.old))
+	nb.insertCells(0, c.new...)
+	return 0, len(c.new) - 1
+}
+
+func (c *replaceCells) undo(nb *Notebook) (int, int) {
+	nb.removeCells(0, len(c.new))
+	nb.insertCells(0, c.old...)
+	return 0, len(c.old) - 1
+}
+
+// apply does the command and adds it to the history, what was undone can no longer be redone.
+func (nb *Notebook) apply(c command) (int, int) {
+	nb.history.undo = append(nb.history.undo, c)
+	nb.history.redo = nil
+	nb.changes++
+	return c.do(nb)
+}
+
+// Undo reverts the last structural change and selects the cells it restored,
+// it returns false when there is nothing to undo.
+func (nb *Notebook) Undo() bool {
+	n := len(nb.history.undo)
+	if n == 0 {
+		return false
+	}
+	c := nb.history.undo[n-1]
+	nb.history.undo = nb.history.undo[:n-1]
+	nb.history.redo = append(nb.history.redo, c)
+	nb.changes++
+	nb.selectRange(c.undo(nb))
+	return true
+}
+
+// Redo applies the last change that was undone again, it returns false when there is nothing to redo.
+func (nb *Notebook) Redo() bool {
+	n := len(nb.history.redo)
+	if n == 0 {
+		return false
+	}
+	c := nb.history.redo[n-1]
+	nb.history.redo = nb.history.redo[:n-1]
+	nb.history.undo = append(nb.history.undo, c)
+	nb.changes++
+	nb.selectRange(c.do(nb))
+	return true
+}
+
+// CanUndo reports whether there are changes to undo.
+func (nb *Notebook) CanUndo() bool {
+	return len(nb.history.undo) > 0
+}
+
+// CanRedo reports whether there are undone changes to redo.
+func (nb *Notebook) CanRedo() bool {
+	return len(nb.history.redo) > 0
+}
+
+// selectRange selects the cells from first to last, the selection keeps the focus
+// so the next undo or redo can follow.
+func (nb *Notebook) selectRange(first, last int) {
+	nb.unfocusSlot()
+	if first < 0 || first >= len(nb.Cells) {
+		nb.selection.Clear()
+		nb.selection.requestFocus = true
+		return
+	}
+	nb.selection.SetFirst(first)
+	nb.selection.SetLast(last)
+}
@@ -0,0 +1,151 @@
+package notebook
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/kernel"
+	"testing"
+)
+
+func newTestNotebook(texts ...string) *Notebook {
+	nb := NewNotebook()
+	var cells cell.Cells
+	for _, txt := range texts {
+		c := cell.NewCell(cell.Input, "", nb.styles)
+		c.SetText(txt)
+		cells = append(cells, c)
+	}
+	nb.AddCells(cells)
+	return nb
+}
+
+func texts(nb *Notebook) []string {
+	var ts []string
+	for _, c := range nb.Cells {
+		ts = append(ts, c.Text())
+	}
+	return ts
+}
+
+func TestUndoInsertCell(t *testing.T) {
+	nb := newTestNotebook("a", "b")
+	assert.False(t, nb.CanUndo())
+	nb.InsertCell(1, cell.H1)
+	assert.Equal(t, 3, nb.Size())
+	assert.Equal(t, cell.H1, nb.Cells[1].Type())
+	assert.Equal(t, 4, len(nb.slots))
+	assert.True(t, nb.Undo())
+	assert.Equal(t, []string{"a", "b"}, texts(nb))
+	assert.Equal(t, 3, len(nb.slots))
+	assert.True(t, nb.Redo())
+	assert.Equal(t, cell.H1, nb.Cells[1].Type())
+	assert.False(t, nb.CanRedo())
+}
+
+func TestUndoDeleteSelected(t *testing.T) {
+	nb := newTestNotebook("a", "b", "c", "d")
+	nb.selection.SetFirst(1)
+	nb.selection.SetLast(2)
+	nb.DeleteSelected()
+	assert.Equal(t, []string{"a", "d"}, texts(nb))
+	assert.False(t, nb.selection.IsSelected(1))
+	assert.True(t, nb.Undo())
+	assert.Equal(t, []string{"a", "b", "c", "d"}, texts(nb))
+	assert.True(t, nb.selection.IsSelected(1))
+	assert.True(t, nb.selection.IsSelected(2))
+	assert.False(t, nb.selection.IsSelected(3))
+	assert.False(t, nb.Undo())
+}
+
+func TestUndoMoveAndType(t *testing.T) {
+	nb := newTestNotebook("a", "b", "c")
+	nb.MoveCell(0, 2)
+	assert.Equal(t, []string{"b", "c", "a"}, texts(nb))
+	nb.SetCellType(2, cell.Code)
+	assert.Equal(t, cell.Code, nb.Cells[2].Type())
+	assert.True(t, nb.Undo())
+	assert.Equal(t, cell.Input, nb.Cells[2].Type())
+	assert.True(t, nb.Undo())
+	assert.Equal(t, []string{"a", "b", "c"}, texts(nb))
+	assert.True(t, nb.Redo())
+	assert.Equal(t, []string{"b", "c", "a"}, texts(nb))
+}
+
+func TestUndoOutput(t *testing.T) {
+	nb := newTestNotebook("1+1")
+	in := nb.Cells[0]
+	job := &kernel.Job{}
+	nb.jobs[job] = in
+	nb.setResult(kernel.Result{Job: job, Prompt: 1})
+	first := nb.Cells[1]
+	job = &kernel.Job{}
+	nb.jobs[job] = in
+	nb.setResult(kernel.Result{Job: job, Prompt: 2, Err: errors.New("failed")})
+	assert.Equal(t, 2, nb.Size())
+	assert.Equal(t, 2, nb.Cells[1].Prompt())
+	assert.True(t, nb.Undo())
+	assert.Equal(t, first, nb.Cells[1])
+	assert.True(t, nb.Undo())
+	assert.Equal(t, 1, nb.Size())
+	nb.DeleteCell(0)
+	assert.False(t, nb.CanRedo())
+}
+
+func TestUndoClearOutputs(t *testing.T) {
+	nb := newTestNotebook("1+1", "2+2", "x")
+	for i := 0; i < 2; i++ {
+		job := &kernel.Job{}
+		nb.jobs[job] = nb.Cells[i*2]
+		nb.setResult(kernel.Result{Job: job, Prompt: i + 1})
+	}
+	assert.Equal(t, 5, nb.Size())
+	nb.ClearOutputs()
+	assert.Equal(t, []string{"1+1", "2+2", "x"}, texts(nb))
+	assert.True(t, nb.Undo())
+	assert.Equal(t, 5, nb.Size())
+	assert.Equal(t, cell.Output, nb.Cells[1].Type())
+	assert.Equal(t, cell.Output, nb.Cells[3].Type())
+	assert.Equal(t, 2, nb.Cells[3].Prompt())
+}
+
+func TestNotebookPut(t *testing.T) {
+	nb := newTestNotebook(`NotebookPut[Append[NotebookGet[], Cell["Done", "Paragraph"]]]`)
+	nb.EvalAll()
+	waitEval(nb)
+	assert.Equal(t, []cell.Type{cell.Input, cell.Paragraph}, types(nb))
+	assert.Equal(t, "Done", nb.Cells[1].Text())
+	assert.True(t, nb.Undo())
+	assert.Equal(t, 1, nb.Size())
+	assert.Equal(t, cell.Input, nb.Cells[0].Type())
+}
+
+func TestMoveSelected(t *testing.T) {
+	nb := newTestNotebook("a", "b", "c", "d")
+	nb.selection.SetFirst(2)
+	nb.selection.SetLast(3)
+	nb.moveSelected(-1)
+	assert.Equal(t, []string{"a", "c", "d", "b"}, texts(nb))
+	assert.False(t, nb.selection.IsSelected(0))
+	assert.True(t, nb.selection.IsSelected(1))
+	assert.True(t, nb.selection.IsSelected(2))
+	assert.False(t, nb.selection.IsSelected(3))
+	nb.moveSelected(1)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, texts(nb))
+	nb.moveSelected(1)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, texts(nb))
+	assert.True(t, nb.Undo())
+	assert.Equal(t, []string{"a", "c", "d", "b"}, texts(nb))
+}
+
+func TestSetTypeSelected(t *testing.T) {
+	nb := newTestNotebook("a", "b", "c")
+	nb.selection.SetFirst(1)
+	nb.selection.SetLast(2)
+	nb.setTypeSelected(cell.Code)
+	assert.Equal(t, cell.Input, nb.Cells[0].Type())
+	assert.Equal(t, cell.Code, nb.Cells[1].Type())
+	assert.Equal(t, cell.Code, nb.Cells[2].Type())
+	assert.True(t, nb.Undo())
+	assert.Equal(t, cell.Input, nb.Cells[2].Type())
+}
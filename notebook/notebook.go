@@ -3,31 +3,89 @@ package notebook
 import (
 	"encoding/xml"
 	. "gioui.org/layout"
-	"github.com/corywalker/expreduce/expreduce"
 	"github.com/wrnrlr/foxtrot/cell"
+	"github.com/wrnrlr/foxtrot/editor"
+	"github.com/wrnrlr/foxtrot/kernel"
 	"github.com/wrnrlr/foxtrot/theme"
 	"io/ioutil"
 )
 
 type Notebook struct {
-	Cells       cell.Cells
-	slots       []*Slot
-	kernel      *expreduce.EvalState
-	promptCount int
+	Cells  cell.Cells
+	slots  []*Slot
+	kernel *kernel.Kernel
+	worker *kernel.Worker
+	jobs   map[*kernel.Job]cell.Cell
 
 	activeSlot int
 	list       List
 	selection  *Selection
 	styles     *theme.Styles
+	history    History
+	// changes counts the structural changes, with the versions of the cells it tells whether the notebook is dirty.
+	changes int
+	saved   savedState
+	// batch is the evaluation of several cells that is in progress, stopped are the cells that
+	// were not evaluated because a cell before them failed.
+	batch   batch
+	stopped cell.Cells
 }
 
+// savedState is the number of structural changes and the versions of the cells when the notebook was saved.
+type savedState struct {
+	changes  int
+	versions map[cell.Cell]int
+}
+
+// NewNotebook returns an empty notebook with its own kernel.
 func NewNotebook() *Notebook {
-	kernel := expreduce.NewEvalState()
+	return NewNotebookWithKernel(kernel.NewKernel())
+}
+
+// NewNotebookWithKernel returns an empty notebook that evaluates its cells with k,
+// k can be shared by several notebooks.
+func NewNotebookWithKernel(k *kernel.Kernel) *Notebook {
+	editor.SetBuiltins(k.Builtins())
 	firstSlot := NewSlot()
 	adds := []*Slot{firstSlot}
 	selection := NewSelection()
 	styles := theme.DefaultStyles()
-	return &Notebook{nil, adds, kernel, 1, 0, List{Axis: Vertical}, selection, styles}
+	w := kernel.NewWorker(k)
+	jobs := map[*kernel.Job]cell.Cell{}
+	return &Notebook{Cells: nil, slots: adds, kernel: k, worker: w, jobs: jobs, activeSlot: 0,
+		list: List{Axis: Vertical}, selection: selection, styles: styles}
+}
+
+// Kernel is the kernel that evaluates the cells of the notebook.
+func (nb *Notebook) Kernel() *kernel.Kernel {
+	return nb.kernel
+}
+
+// Close stops the evaluation of the cells of the notebook.
+func (nb *Notebook) Close() {
+	nb.worker.Close()
+}
+
+// Dirty reports whether the notebook changed since it was marked as saved.
+func (nb *Notebook) Dirty() bool {
+	if nb.changes != nb.saved.changes {
+		return true
+	}
+	for _, c := range nb.Cells {
+		if v, ok := nb.saved.versions[c]; !ok || v != c.Version() {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkSaved marks the current content of the notebook as saved.
+func (nb *Notebook) MarkSaved() {
+	nb.saved.changes = nb.changes
+	nb.saved.versions = map[cell.Cell]int{}
+	for _, c := range nb.Cells {
+		nb.saved.versions[c] = c.Version()
+	}
 }
 
 func (nb *Notebook) isOutputCell(i int) bool {
@@ -65,36 +123,130 @@ func (nb *Notebook) AddCells(cells cell.Cells) {
 	nb.selection.Size = len(nb.Cells)
 }
 
+// InsertCell adds a new cell of type typ at index.
 func (nb *Notebook) InsertCell(index int, typ cell.Type) {
-	nb.slots = append(nb.slots, NewSlot())
-	c := cell.NewCell(typ, "Content[ ]:=", nb.styles)
-	nb.Cells = append(nb.Cells, c)
-	copy(nb.Cells[index+1:], nb.Cells[index:])
-	nb.Cells[index] = c
-	nb.selection.Size = len(nb.Cells)
+	nb.apply(&insertCells{index, cell.Cells{nb.newCell(typ)}})
+}
+
+// AddInput adds an input cell with the text s after the last cell and focuses it.
+func (nb *Notebook) AddInput(s string) {
+	c := nb.newCell(cell.Input)
+	c.SetText(s)
+	i := len(nb.Cells)
+	nb.apply(&insertCells{i, cell.Cells{c}})
+	nb.focusCell(i)
 }
 
+// DeleteCell removes the cell at index i.
 func (nb *Notebook) DeleteCell(i int) {
-	if i < len(nb.Cells)-1 {
-		copy(nb.Cells[i:], nb.Cells[i+1:])
-	}
-	nb.Cells[len(nb.Cells)-1] = nil // or the zero value of T
-	nb.Cells = nb.Cells[:len(nb.Cells)-1]
-	nb.slots = nb.slots[:len(nb.Cells)+1]
-	nb.selection.Size = len(nb.Cells)
+	nb.apply(&deleteCells{i, cell.Cells{nb.Cells[i]}})
 }
 
+// DeleteSelected removes the selected cells.
 func (nb *Notebook) DeleteSelected() {
-	unselectedCount := 0
-	for i, c := range nb.Cells {
-		if !nb.selection.IsSelected(i) {
-			nb.Cells[unselectedCount] = c
-			unselectedCount++
+	first, last := -1, -1
+	for i := range nb.Cells {
+		if nb.selection.IsSelected(i) {
+			if first == -1 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first == -1 {
+		return
+	}
+	deleted := append(cell.Cells{}, nb.Cells[first:last+1]...)
+	nb.selectRange(nb.apply(&deleteCells{first, deleted}))
+}
+
+// ClearOutputs removes all output cells, the outputs of cells that are still evaluated are added when they arrive.
+func (nb *Notebook) ClearOutputs() {
+	c := &clearOutputs{}
+	for i, o := range nb.Cells {
+		if o.Type() == cell.Output {
+			c.indexes = append(c.indexes, i)
+			c.cells = append(c.cells, o)
+		}
+	}
+	if len(c.indexes) > 0 {
+		nb.apply(c)
+	}
+}
+
+// MoveCell moves the cell at index from to index to.
+func (nb *Notebook) MoveCell(from, to int) {
+	if from == to || from < 0 || from >= len(nb.Cells) || to < 0 || to >= len(nb.Cells) {
+		return
+	}
+	nb.selectRange(nb.apply(&moveCell{from, to}))
+}
+
+// SetCellType changes the type of the cell at index i.
+func (nb *Notebook) SetCellType(i int, typ cell.Type) {
+	if from := nb.Cells[i].Type(); from != typ {
+		nb.apply(&setType{i, from, typ})
+	}
+}
+
+// moveSelected moves the selected cells up or down by one cell, the cell next to them takes their place.
+func (nb *Notebook) moveSelected(delta int) {
+	if nb.selection.first == -1 {
+		return
+	}
+	first, last := nb.selection.min(), nb.selection.max()
+	if first+delta < 0 || last+delta >= len(nb.Cells) {
+		return
+	}
+	if delta < 0 {
+		nb.MoveCell(first-1, last)
+	} else {
+		nb.MoveCell(last+1, first)
+	}
+	nb.selectRange(first+delta, last+delta)
+}
+
+// setTypeSelected changes the type of the selected cells, output cells keep their type.
+func (nb *Notebook) setTypeSelected(typ cell.Type) {
+	if nb.selection.first == -1 {
+		return
+	}
+	for i := nb.selection.min(); i <= nb.selection.max() && i < len(nb.Cells); i++ {
+		if nb.Cells[i].Type() != cell.Output {
+			nb.SetCellType(i, typ)
 		}
 	}
-	nb.Cells = nb.Cells[:unselectedCount]
-	nb.slots = nb.slots[:unselectedCount+1]
-	nb.selection.Size = unselectedCount
+}
+
+func (nb *Notebook) newCell(typ cell.Type) cell.Cell {
+	return cell.NewCell(typ, "Content[ ]:=", nb.styles)
+}
+
+// insertCells adds cells at index i, every cell has a slot before it and there is one more slot after the last cell.
+func (nb *Notebook) insertCells(i int, cells ...cell.Cell) {
+	for range cells {
+		nb.slots = append(nb.slots, NewSlot())
+	}
+	nb.Cells = append(nb.Cells[:i], append(append(cell.Cells{}, cells...), nb.Cells[i:]...)...)
+	nb.selection.Size = len(nb.Cells)
+}
+
+// removeCells removes n cells starting at index i and returns them.
+func (nb *Notebook) removeCells(i, n int) cell.Cells {
+	removed := append(cell.Cells{}, nb.Cells[i:i+n]...)
+	nb.Cells = append(nb.Cells[:i], nb.Cells[i+n:]...)
+	nb.slots = nb.slots[:len(nb.Cells)+1]
+	nb.selection.Size = len(nb.Cells)
+	return removed
+}
+
+// PromptCount is the number of the next In[n] label.
+func (nb *Notebook) PromptCount() int {
+	return nb.kernel.PromptCount()
+}
+
+func (nb *Notebook) SetPromptCount(n int) {
+	nb.kernel.SetPromptCount(n)
 }
 
 func (nb *Notebook) Size() int {
@@ -111,4 +263,5 @@ func WriteNotebookFile(path string, notebook *Notebook) error {
 	return nil
 }
 
-type SaveNotebookEvent struct{}
+// SaveNotebookEvent is returned by Event when the notebook should be saved, to a new file when As is set.
+type SaveNotebookEvent struct{ As bool }
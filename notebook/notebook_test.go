@@ -3,6 +3,7 @@ package notebook
 import (
 	"gioui.org/io/event"
 	"gioui.org/layout"
+	"gioui.org/op"
 	"github.com/stretchr/testify/assert"
 	"github.com/wrnrlr/foxtrot/cell"
 	"github.com/wrnrlr/foxtrot/theme"
@@ -10,7 +11,7 @@ import (
 )
 
 func TestNewNotebook(t *testing.T) {
-	nb := NewNotebook(nil)
+	nb := NewNotebook()
 	assert.Equal(t, 0, nb.Size())
 }
 
@@ -19,7 +20,8 @@ func TestNewNotebookWithCells(t *testing.T) {
 	var cells cell.Cells
 	c := cell.NewCell(cell.Input, "Content[0]:=", style)
 	cells = append(cells, c)
-	nb := NewNotebook(nil)
+	nb := NewNotebook()
+	nb.AddCells(cells)
 	assert.Equal(t, 1, nb.Size())
 }
 
@@ -28,11 +30,41 @@ func TestDeleteCell(t *testing.T) {
 	var cells cell.Cells
 	c := cell.NewCell(cell.Input, "Content[0]:=", style)
 	cells = append(cells, c)
-	nb := NewNotebook(nil)
+	nb := NewNotebook()
+	nb.AddCells(cells)
 	nb.DeleteCell(0)
 	assert.Equal(t, 0, nb.Size())
 }
 
+func TestAddInput(t *testing.T) {
+	nb := NewNotebook()
+	nb.AddInput("Sin[Pi]")
+	assert.Equal(t, 1, nb.Size())
+	assert.Equal(t, cell.Input, nb.Cells[0].Type())
+	assert.Equal(t, "Sin[Pi]", nb.Cells[0].Text())
+	nb.Undo()
+	assert.Equal(t, 0, nb.Size())
+}
+
+func TestDirty(t *testing.T) {
+	style := theme.DefaultStyles()
+	c := cell.NewCell(cell.Input, "", style)
+	c.SetText("1+1")
+	nb := NewNotebook()
+	nb.AddCells(cell.Cells{c})
+	nb.MarkSaved()
+	assert.False(t, nb.Dirty())
+	c.SetText("1+2")
+	assert.True(t, nb.Dirty())
+	nb.MarkSaved()
+	assert.False(t, nb.Dirty())
+	nb.InsertCell(1, cell.Paragraph)
+	assert.True(t, nb.Dirty())
+	nb.MarkSaved()
+	nb.Undo()
+	assert.True(t, nb.Dirty())
+}
+
 type evalCell struct {
 	cell.Cell
 	event interface{}
@@ -51,14 +83,83 @@ func (queue) Events(k event.Key) []event.Event {
 var q queue
 
 func TestEvalCell(t *testing.T) {
-	gtx := &layout.Context{Queue: q}
+	gtx := &layout.Context{Ops: new(op.Ops), Queue: q}
 	style := theme.DefaultStyles()
 	var cells cell.Cells
 	c := cell.NewCell(cell.Input, "Content[0]:=", style)
 	c.SetText("1+1")
 	ec := &evalCell{Cell: c, event: cell.EvalEvent{}}
 	cells = append(cells, ec)
-	nb := NewNotebook(cells)
+	nb := NewNotebook()
+	nb.AddCells(cells)
+	nb.Event(gtx)
+	assert.Equal(t, 1, nb.Size())
+	for nb.worker.Pending() > 0 {
+		<-nb.Updated()
+	}
 	nb.Event(gtx)
 	assert.Equal(t, 2, nb.Size())
 }
+
+// waitEval waits until the kernel evaluated the queued cells of nb and adds their outputs.
+func waitEval(nb *Notebook) {
+	for nb.worker.Pending() > 0 {
+		<-nb.Updated()
+	}
+	nb.KernelEvents()
+}
+
+func TestEvalAll(t *testing.T) {
+	nb := newTestNotebook("a = 1", "a +", "a + 2")
+	nb.EvalAll()
+	remaining, total := nb.Progress()
+	assert.Equal(t, 3, remaining)
+	assert.Equal(t, 3, total)
+	waitEval(nb)
+	assert.Equal(t, 5, nb.Size())
+	assert.NotNil(t, nb.Cells[3].Err())
+	assert.Equal(t, 1, nb.Stopped())
+	remaining, total = nb.Progress()
+	assert.Equal(t, 0, remaining)
+	assert.Equal(t, 0, total)
+	nb.ContinueEval()
+	waitEval(nb)
+	assert.Equal(t, 6, nb.Size())
+	assert.Equal(t, 0, nb.Stopped())
+	nb.Cells[2].SetText("a + 1")
+	nb.EvalAll()
+	waitEval(nb)
+	assert.Equal(t, 6, nb.Size())
+	assert.Nil(t, nb.Cells[3].Err())
+	assert.Equal(t, cell.Output, nb.Cells[5].Type())
+}
+
+func TestEvalBelow(t *testing.T) {
+	nb := newTestNotebook("1", "2", "3")
+	nb.focusSlot(1)
+	nb.EvalBelow()
+	waitEval(nb)
+	assert.Equal(t, []cell.Type{cell.Input, cell.Input, cell.Output, cell.Input, cell.Output}, types(nb))
+	nb.focusSlot(1)
+	nb.EvalAbove()
+	waitEval(nb)
+	assert.Equal(t, 6, nb.Size())
+	assert.Equal(t, cell.Output, nb.Cells[1].Type())
+}
+
+func types(nb *Notebook) []cell.Type {
+	var ts []cell.Type
+	for _, c := range nb.Cells {
+		ts = append(ts, c.Type())
+	}
+	return ts
+}
+
+func TestEvalAllRuntimeFailure(t *testing.T) {
+	nb := newTestNotebook("a = 1", "Throw[a]", "a + 2")
+	nb.EvalAll()
+	waitEval(nb)
+	assert.Equal(t, 5, nb.Size())
+	assert.NotNil(t, nb.Cells[3].Err())
+	assert.Equal(t, 1, nb.Stopped())
+}
@@ -3,6 +3,7 @@ package notebook
 import (
 	"gioui.org/io/key"
 	"gioui.org/layout"
+	"github.com/wrnrlr/foxtrot/cell"
 )
 
 // Select a range of Cells from first to last
@@ -42,6 +43,26 @@ func (s *Selection) processEvents(gtx *layout.Context) {
 		case key.Event:
 			if ke.Name == key.NameDeleteBackward || ke.Name == key.NameDeleteForward {
 				s.events = append(s.events, DeleteSelected{})
+			} else if ke.Name == "." && ke.Modifiers.Contain(key.ModCommand) {
+				s.events = append(s.events, AbortSelected{})
+			} else if ke.Name == "Z" && ke.Modifiers.Contain(key.ModCommand) && ke.Modifiers.Contain(key.ModShift) {
+				s.events = append(s.events, RedoEvent{})
+			} else if ke.Name == "Z" && ke.Modifiers.Contain(key.ModCommand) {
+				s.events = append(s.events, UndoEvent{})
+			} else if ke.Name == "C" && ke.Modifiers.Contain(key.ModCommand) {
+				s.events = append(s.events, CopySelected{})
+			} else if ke.Name == "X" && ke.Modifiers.Contain(key.ModCommand) {
+				s.events = append(s.events, CutSelected{})
+			} else if ke.Name == "V" && ke.Modifiers.Contain(key.ModCommand) {
+				s.events = append(s.events, PasteEvent{})
+			} else if ke.Name == "S" && ke.Modifiers.Contain(key.ModCommand) {
+				s.events = append(s.events, SaveNotebookEvent{As: ke.Modifiers.Contain(key.ModShift)})
+			} else if ke.Name == key.NameUpArrow && ke.Modifiers.Contain(key.ModCommand) && ke.Modifiers.Contain(key.ModShift) {
+				s.events = append(s.events, MoveSelected{Delta: -1})
+			} else if ke.Name == key.NameDownArrow && ke.Modifiers.Contain(key.ModCommand) && ke.Modifiers.Contain(key.ModShift) {
+				s.events = append(s.events, MoveSelected{Delta: 1})
+			} else if typ, ok := typeKeys[ke.Name]; ok && ke.Modifiers.Contain(key.ModCommand) {
+				s.events = append(s.events, SetTypeSelected{Type: typ})
 			} else if ke.Name == key.NameUpArrow && ke.Modifiers.Contain(key.ModShift) {
 				s.SetLast(s.last - 1)
 			} else if ke.Name == key.NameDownArrow && ke.Modifiers.Contain(key.ModShift) {
@@ -104,6 +125,43 @@ func (s *Selection) max() int {
 
 type DeleteSelected struct{}
 
+type AbortSelected struct{}
+
+type CopySelected struct{}
+
+type CutSelected struct{}
+
+// MoveSelected is generated when the selected cells should move up or down by one cell.
+type MoveSelected struct {
+	Delta int
+}
+
+// SetTypeSelected is generated when the selected cells should change their type.
+type SetTypeSelected struct {
+	Type cell.Type
+}
+
+// typeKeys are the keys that change the type of the selected cells together with Cmd,
+// in the order of the keys that add a cell in a slot.
+var typeKeys = map[string]cell.Type{
+	"1": cell.H1,
+	"2": cell.H2,
+	"3": cell.H3,
+	"4": cell.H4,
+	"5": cell.Paragraph,
+	"6": cell.Code,
+	"7": cell.Input,
+}
+
+// PasteEvent is generated when the cells on the clipboard should be inserted after the selection.
+type PasteEvent struct{}
+
+// UndoEvent is generated when the last structural change to the notebook should be undone.
+type UndoEvent struct{}
+
+// RedoEvent is generated when the last undone change should be applied again.
+type RedoEvent struct{}
+
 type FocusSlotEvent struct {
 	Index int
 }
@@ -1,7 +1,12 @@
 package notebook
 
 import (
+	"gioui.org/io/event"
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/op"
 	"github.com/stretchr/testify/assert"
+	"github.com/wrnrlr/foxtrot/cell"
 	"testing"
 )
 
@@ -38,3 +43,20 @@ func TestClear(t *testing.T) {
 	assert.False(t, s.IsSelected(2))
 	assert.False(t, s.IsSelected(3))
 }
+
+type keyQueue []event.Event
+
+func (q keyQueue) Events(k event.Key) []event.Event {
+	return q
+}
+
+func TestMoveAndTypeKeys(t *testing.T) {
+	s := NewSelection()
+	gtx := &layout.Context{Ops: new(op.Ops), Queue: keyQueue{
+		key.Event{Name: key.NameUpArrow, Modifiers: key.ModCommand | key.ModShift},
+		key.Event{Name: key.NameDownArrow, Modifiers: key.ModCommand | key.ModShift},
+		key.Event{Name: "5", Modifiers: key.ModCommand},
+		key.Event{Name: "5"},
+	}}
+	assert.Equal(t, []interface{}{MoveSelected{Delta: -1}, MoveSelected{Delta: 1}, SetTypeSelected{Type: cell.Paragraph}}, s.Event(gtx))
+}
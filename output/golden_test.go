@@ -0,0 +1,170 @@
+package output_test
+
+import (
+	"flag"
+	"fmt"
+	"gioui.org/font"
+	"gioui.org/font/gofont"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"github.com/wrnrlr/foxtrot/colors"
+	"github.com/wrnrlr/foxtrot/kernel"
+	"github.com/wrnrlr/foxtrot/output"
+	"github.com/wrnrlr/foxtrot/style"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "write the rendered output to the golden files in testdata/golden")
+
+const (
+	// channelTolerance is the difference of a color channel that still counts as the same pixel,
+	// it absorbs small differences in anti-aliasing.
+	channelTolerance = 48
+	// pixelTolerance is the fraction of pixels that may differ before an image fails.
+	pixelTolerance = 0.01
+)
+
+var goldenCases = []struct {
+	name, src string
+}{
+	{"fraction", "1/2"},
+	{"fraction_of_symbols", "a/b"},
+	{"power", "x^2"},
+	{"power_of_sum", "(a+b)^3"},
+	{"sqrt", "Sqrt[x]"},
+	{"sum", "a+b"},
+	{"list", "{1, 2, 3}"},
+	{"nested_list", "{1, {x^2, {1/3, Sqrt[y]}}}"},
+	{"wrapped_list", "Range[60]"},
+	{"graphics_circle", "Graphics[Circle[]]"},
+	{"graphics_primitives", "Graphics[{Rectangle[{0, 0}, {2, 1}], RGBColor[1, 0, 0], Line[{{0, 0}, {1, 1}, {2, 0}}]}]"},
+}
+
+var registerFonts sync.Once
+
+func goldenStyle() style.Style {
+	registerFonts.Do(gofont.Register)
+	return style.Style{
+		Font:   text.Font{Size: unit.Sp(16)},
+		Shaper: font.Default(),
+		Color:  colors.Black,
+	}
+}
+
+func TestGolden(t *testing.T) {
+	k := kernel.NewKernel()
+	for _, c := range goldenCases {
+		t.Run(c.name, func(t *testing.T) {
+			ex, err := k.Eval(c.src)
+			if err != nil {
+				t.Fatalf("failed to evaluate %s: %v", c.src, err)
+			}
+			img := output.Render(ex, goldenStyle(), 1)
+			path := filepath.Join("testdata", "golden", c.name+".png")
+			if *update {
+				if err := writePNG(path, img); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			golden, err := readPNG(path)
+			if os.IsNotExist(err) {
+				t.Fatalf("no golden image %s, create it with go test ./output -update", path)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff, ok := compareImages(golden, img); !ok {
+				actual := filepath.Join(os.TempDir(), "foxtrot-"+c.name+".png")
+				writePNG(actual, img)
+				t.Errorf("%s differs from %s: %s, the rendered image is written to %s", c.src, path, diff, actual)
+			}
+		})
+	}
+}
+
+func TestCompareImages(t *testing.T) {
+	a := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	b := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	if _, ok := compareImages(a, b); !ok {
+		t.Error("equal images should match")
+	}
+	b.Pix[0] = channelTolerance
+	if _, ok := compareImages(a, b); !ok {
+		t.Error("a difference within the channel tolerance should match")
+	}
+	b.Pix[4] = 255
+	b.Pix[8] = 255
+	if _, ok := compareImages(a, b); ok {
+		t.Error("2% of different pixels should not match")
+	}
+	if _, ok := compareImages(a, image.NewRGBA(image.Rect(0, 0, 10, 11))); ok {
+		t.Error("images of a different size should not match")
+	}
+}
+
+// compareImages reports whether got matches want within channelTolerance and pixelTolerance.
+func compareImages(want, got image.Image) (string, bool) {
+	if want.Bounds().Size() != got.Bounds().Size() {
+		return "size " + got.Bounds().Size().String() + " instead of " + want.Bounds().Size().String(), false
+	}
+	wb, gb := want.Bounds(), got.Bounds()
+	different := 0
+	for y := 0; y < wb.Dy(); y++ {
+		for x := 0; x < wb.Dx(); x++ {
+			r1, g1, b1, a1 := want.At(wb.Min.X+x, wb.Min.Y+y).RGBA()
+			r2, g2, b2, a2 := got.At(gb.Min.X+x, gb.Min.Y+y).RGBA()
+			if channelDiff(r1, r2) || channelDiff(g1, g2) || channelDiff(b1, b2) || channelDiff(a1, a2) {
+				different++
+			}
+		}
+	}
+	total := wb.Dx() * wb.Dy()
+	if total > 0 && float64(different)/float64(total) > pixelTolerance {
+		return fmt.Sprintf("%d of %d pixels are different", different, total), false
+	}
+	return "", true
+}
+
+func channelDiff(a, b uint32) bool {
+	// RGBA returns 16 bit channels.
+	a, b = a>>8, b>>8
+	if a > b {
+		return a-b > channelTolerance
+	}
+	return b-a > channelTolerance
+}
+
+func readPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return png.Decode(file)
+}
+
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := ioutil.TempFile(filepath.Dir(path), "golden")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,29 @@
+package output
+
+import (
+	"gioui.org/f32"
+	"gioui.org/unit"
+	api "github.com/corywalker/expreduce/pkg/expreduceapi"
+	"github.com/wrnrlr/foxtrot/raster"
+	"github.com/wrnrlr/foxtrot/style"
+	"image"
+)
+
+// MaxRenderWidth is the width at which Render wraps long output, in sp.
+const MaxRenderWidth = 800
+
+// Render typesets ex like FromEx and rasterizes it without a GPU, scale is the number of pixels per sp.
+// The output is drawn in s.Color on a white background with a small margin.
+func Render(ex api.Ex, s style.Style, scale float32) *image.RGBA {
+	gtx := raster.NewContext(scale, image.Point{X: int(MaxRenderWidth * scale), Y: int(MaxRenderWidth * scale)})
+	shape := FromEx(ex, gtx)
+	dims := shape.Dimensions(gtx, s)
+	margin := gtx.Px(unit.Sp(4))
+	size := dims.Size.Add(image.Point{X: 2 * margin, Y: 2 * margin})
+	c := raster.NewCanvas(size)
+	c.Color = s.Color
+	c.Push(f32.Point{X: float32(margin), Y: float32(margin)})
+	shape.Rasterize(gtx, s, c)
+	c.Pop()
+	return c.Image
+}
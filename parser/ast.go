@@ -5,6 +5,3 @@ type Position struct {
 }
 
 type ExpressionCase int
-
-type Tag struct {
-}
@@ -12,9 +12,9 @@ type Expression struct {
 	Tag  *Tag
 	Tag2 *Tag
 
-	Token  Value
-	Token2 Value
-	Token3 Value
+	Token  Token
+	Token2 Token
+	Token3 Token
 
 	Pos Position
 }
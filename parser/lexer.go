@@ -13,22 +13,31 @@ Whitespace = " " | "\t" | "\n" | "\r" .
 `
 
 func isWhitespace(ch rune) bool {
-	return ch == ' ' || ch == '\t' || ch == '\n'
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
 }
 
 func isLetter(ch rune) bool {
-	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || ch == '$' || ch > unicode.MaxASCII && unicode.IsLetter(ch)
 }
 
 func isDigit(ch rune) bool {
 	return unicode.IsDigit(ch)
 }
 
-func isOperator(ch rune) bool {
+func isBracket(ch rune) bool {
 	switch ch {
-	case '!', '[', ']', '(', ')', '^', '+', '-', '*', '/', '=', '<', '>', ',', ';', '{', '}', '&':
+	case '[', ']', '(', ')', '{', '}':
 		return true
 	default:
 		return false
 	}
 }
+
+func isOperator(ch rune) bool {
+	switch ch {
+	case '!', '^', '+', '-', '*', '/', '=', '<', '>', ',', ';', '&', '@', '.', ':', '?', '~', '|', '\'', '%':
+		return true
+	default:
+		return ch > unicode.MaxASCII && (unicode.IsSymbol(ch) || unicode.IsPunct(ch))
+	}
+}
@@ -6,9 +6,8 @@ import "io"
 type Parser struct {
 	s   *Scanner
 	buf struct {
-		tok Token  // last read token
-		lit string // last read literal
-		n   int    // buffer size (max=1)
+		tok Token // last read token
+		n   int   // buffer size (max=1)
 	}
 }
 
@@ -23,18 +22,18 @@ func (p *Parser) Parse() (*Expression, error) {
 
 // scan returns the next token from the underlying scanner.
 // If a token has been unscanned then read that instead.
-func (p *Parser) scan() (tok Token, lit string) {
+func (p *Parser) scan() (tok Token) {
 	// If we have a token on the buffer, then return it.
 	if p.buf.n != 0 {
 		p.buf.n = 0
-		return p.buf.tok, p.buf.lit
+		return p.buf.tok
 	}
 
 	// Otherwise read the next token from the scanner.
-	tok, lit = p.s.Scan()
+	tok = p.s.Scan()
 
 	// Save it to the buffer in case we unscan later.
-	p.buf.tok, p.buf.lit = tok, lit
+	p.buf.tok = tok
 
 	return
 }
@@ -43,10 +42,10 @@ func (p *Parser) scan() (tok Token, lit string) {
 func (p *Parser) unscan() { p.buf.n = 1 }
 
 // scanIgnoreWhitespace scans the next non-whitespace token.
-func (p *Parser) scanIgnoreWhitespace() (tok Token, lit string) {
-	tok, lit = p.scan()
-	if tok == WS {
-		tok, lit = p.scan()
+func (p *Parser) scanIgnoreWhitespace() (tok Token) {
+	tok = p.scan()
+	if tok.Kind == Whitespace {
+		tok = p.scan()
 	}
 	return
 }
@@ -25,7 +25,10 @@ func TestParserAddition(t *testing.T) {
 
 func exampleAST(s string) *Expression {
 	expr := &Expression{}
-	parser, _ := participle.Build(&Expression{})
+	parser, err := participle.Build(&Expression{})
+	if err != nil {
+		return nil
+	}
 	parser.ParseString(s, expr)
 	return expr
 }
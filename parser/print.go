@@ -1,22 +1,20 @@
 package parser
 
-import "github.com/cznic/strutil"
+import (
+	"github.com/cznic/strutil"
+	"reflect"
+)
 
 var (
-	testFile *token.File // Testing hook
-
 	hooks = strutil.PrettyPrintHooks{
 		reflect.TypeOf(Token{}): func(f strutil.Formatter, v interface{}, prefix string, suffix string) {
 			t := v.(Token)
-			if t.Rune == 0 {
+			if t.Kind == EOF {
 				return
 			}
 
 			f.Format(prefix)
-			if testFile != nil {
-				f.Format("%s: ", testFile.Position(t.Pos()))
-			}
-			f.Format("%s", yySymName(int(t.Rune)))
+			f.Format("%d:%d: %s", t.Pos.Line, t.Pos.Column, t.Kind)
 			if t.Val != "" {
 				f.Format(", %q", t.Val)
 			}
@@ -1,123 +1,250 @@
 package parser
 
 import (
-	"bufio"
-	"bytes"
 	"io"
+	"io/ioutil"
 	"strings"
+	"unicode/utf8"
 )
 
+// State is what the scanner needs to know to continue in the middle of a text,
+// like at the start of a line that follows an unterminated string or comment.
+type State struct {
+	// Comment is the depth of the nested comments.
+	Comment int
+	// String is set when inside a string.
+	String bool
+}
+
 // Scanner represents a lexical scanner.
 type Scanner struct {
-	r *bufio.Reader
+	src   string
+	pos   Position
+	state State
 }
 
 // NewScanner returns a new instance of Scanner.
 func NewScanner(r io.Reader) *Scanner {
-	return &Scanner{r: bufio.NewReader(r)}
+	b, _ := ioutil.ReadAll(r)
+	return NewStateScanner(string(b), State{})
+}
+
+// NewStateScanner returns a scanner for src that starts in state st.
+func NewStateScanner(src string, st State) *Scanner {
+	return &Scanner{src: src, pos: Position{Line: 1, Column: 1}, state: st}
+}
+
+// State returns the state of the scanner at the current position.
+func (s *Scanner) State() State {
+	return s.state
 }
 
 var eof = rune(0)
 
-// read reads the next rune from the bufferred reader.
-// Returns the rune(0) if an error occurs (or io.EOF is returned).
-func (s *Scanner) read() rune {
-	ch, _, err := s.r.ReadRune()
-	if err != nil {
+// peek returns the rune at n bytes after the current position.
+func (s *Scanner) peek(n int) rune {
+	if s.pos.Offset+n >= len(s.src) {
 		return eof
 	}
+	ch, _ := utf8.DecodeRuneInString(s.src[s.pos.Offset+n:])
 	return ch
 }
 
-// unread places the previously read rune back on the reader.
-func (s *Scanner) unread() { _ = s.r.UnreadRune() }
-
-// Scan returns the next token and literal value.
-func (s *Scanner) Scan() (tok token, lit string) {
-	// Read the next rune.
-	ch := s.read()
-
-	// If we see whitespace then consume all contiguous whitespace.
-	// If we see a letter then consume as an ident or reserved word.
-	if isWhitespace(ch) {
-		s.unread()
-		return s.scanWhitespace()
-	} else if isLetter(ch) || isDigit(ch) || isoperator(ch) {
-		s.unread()
-		return s.scanIdent()
+// read consumes the next rune.
+// Returns the rune(0) at the end of the input.
+func (s *Scanner) read() rune {
+	if s.pos.Offset >= len(s.src) {
+		return eof
 	}
-
-	// Otherwise read the individual character.
-	switch ch {
-	case eof:
-		return EOF, ""
-	case '*':
-		return ASTERISK, string(ch)
-	case ',':
-		return COMMA, string(ch)
+	ch, size := utf8.DecodeRuneInString(s.src[s.pos.Offset:])
+	s.pos.Offset += size
+	if ch == '\n' {
+		s.pos.Line++
+		s.pos.Column = 1
+	} else {
+		s.pos.Column++
 	}
-
-	return ILLEGAL, string(ch)
+	return ch
 }
 
-type token int
-
-const (
-	EOF token = iota
-	ASTERISK
-	COMMA
-	ILLEGAL
-	WS
-)
-
-// scanWhitespace consumes the current rune and all contiguous whitespace.
-func (s *Scanner) scanWhitespace() (tok token, lit string) {
-	// Create a buffer and read the current character into it.
-	var buf bytes.Buffer
-	buf.WriteRune(s.read())
+// Scan returns the next token, its position is the position of the first rune.
+func (s *Scanner) Scan() Token {
+	start := s.pos
+	kind := s.scan()
+	return Token{Kind: kind, Val: s.src[start.Offset:s.pos.Offset], Pos: start}
+}
 
-	// Read every subsequent whitespace character into the buffer.
-	// Non-whitespace characters and EOF will cause the loop to exit.
+// ScanAll returns the tokens up to the end of the input.
+func (s *Scanner) ScanAll() []Token {
+	var tokens []Token
 	for {
-		if ch := s.read(); ch == eof {
-			break
-		} else if !isWhitespace(ch) {
-			s.unread()
-			break
-		} else {
-			buf.WriteRune(ch)
+		t := s.Scan()
+		if t.Kind == EOF {
+			return tokens
 		}
+		tokens = append(tokens, t)
 	}
+}
 
-	return WS, buf.String()
+func (s *Scanner) scan() Kind {
+	ch := s.peek(0)
+	if ch == eof {
+		return EOF
+	}
+	if s.state.Comment > 0 {
+		s.scanComment()
+		return Comment
+	}
+	if s.state.String {
+		s.scanString()
+		return String
+	}
+	switch {
+	case isWhitespace(ch):
+		for isWhitespace(s.peek(0)) {
+			s.read()
+		}
+		return Whitespace
+	case ch == '(' && s.peek(1) == '*':
+		s.read()
+		s.read()
+		s.state.Comment = 1
+		s.scanComment()
+		return Comment
+	case ch == '"':
+		s.read()
+		s.state.String = true
+		s.scanString()
+		return String
+	case isDigit(ch) || ch == '.' && isDigit(s.peek(1)):
+		s.scanNumber()
+		return Number
+	case isLetter(ch):
+		s.scanSymbol()
+		if s.peek(0) == '_' {
+			s.scanBlank()
+			return Pattern
+		}
+		return Symbol
+	case ch == '_':
+		s.scanBlank()
+		return Pattern
+	case ch == '#':
+		s.read()
+		if s.peek(0) == '#' {
+			s.read()
+		}
+		for isDigit(s.peek(0)) || isLetter(s.peek(0)) {
+			s.read()
+		}
+		return Slot
+	case ch == '<' && s.peek(1) == '|', ch == '|' && s.peek(1) == '>':
+		s.read()
+		s.read()
+		return Bracket
+	case isBracket(ch):
+		s.read()
+		return Bracket
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(s.src[s.pos.Offset:], op) {
+			for range op {
+				s.read()
+			}
+			return Operator
+		}
+	}
+	s.read()
+	if isOperator(ch) {
+		return Operator
+	}
+	return Illegal
 }
 
-func (s *Scanner) scanIdent() (tok Token, lit string) {
-	// Create a buffer and read the current character into it.
-	var buf bytes.Buffer
-	buf.WriteRune(s.read())
+// scanComment consumes the rest of the comment, comments can be nested.
+func (s *Scanner) scanComment() {
+	for s.state.Comment > 0 {
+		switch ch := s.read(); {
+		case ch == eof:
+			return
+		case ch == '(' && s.peek(0) == '*':
+			s.read()
+			s.state.Comment++
+		case ch == '*' && s.peek(0) == ')':
+			s.read()
+			s.state.Comment--
+		}
+	}
+}
 
-	// Read every subsequent ident character into the buffer.
-	// Non-ident characters and EOF will cause the loop to exit.
+// scanString consumes the rest of the string including the closing quote.
+func (s *Scanner) scanString() {
 	for {
-		if ch := s.read(); ch == eof {
-			break
-		} else if !isLetter(ch) && !isDigit(ch) && ch != '_' {
-			s.unread()
-			break
-		} else {
-			_, _ = buf.WriteRune(ch)
+		switch s.read() {
+		case eof:
+			return
+		case '\\':
+			s.read()
+		case '"':
+			s.state.String = false
+			return
+		}
+	}
+}
+
+// scanNumber consumes numbers like 12, 1.5, .5, 1.5`20 and 1.5*^-3.
+func (s *Scanner) scanNumber() {
+	digits := func() {
+		for isDigit(s.peek(0)) {
+			s.read()
+		}
+	}
+	digits()
+	if s.peek(0) == '.' && s.peek(1) != '.' {
+		s.read()
+		digits()
+	}
+	if s.peek(0) == '`' {
+		s.read()
+		digits()
+		if s.peek(0) == '.' {
+			s.read()
+			digits()
+		}
+	}
+	if s.peek(0) == '*' && s.peek(1) == '^' {
+		s.read()
+		s.read()
+		if s.peek(0) == '-' {
+			s.read()
 		}
+		digits()
 	}
+}
+
+// scanSymbol consumes a symbol that may have a context like System`Plus.
+func (s *Scanner) scanSymbol() {
+	for ch := s.peek(0); isLetter(ch) || isDigit(ch) || ch == '`'; ch = s.peek(0) {
+		s.read()
+	}
+}
 
-	// If the string matches a keyword then return that keyword.
-	switch strings.ToUpper(buf.String()) {
-	case "SELECT":
-		return SELECT, buf.String()
-	case "FROM":
-		return FROM, buf.String()
+// scanBlank consumes _, __ or ___ with an optional head and an optional default dot like x_. .
+func (s *Scanner) scanBlank() {
+	for i := 0; i < 3 && s.peek(0) == '_'; i++ {
+		s.read()
+	}
+	if isLetter(s.peek(0)) {
+		s.scanSymbol()
+	} else if s.peek(0) == '.' && !isDigit(s.peek(1)) && s.peek(1) != '.' {
+		s.read()
 	}
+}
 
-	// Otherwise return as a regular identifier.
-	return IDENT, buf.String()
+// operators that are longer than one rune, longest first so the scanner takes the longest match.
+var operators = []string{
+	"===", "=!=", "//.", "//@", "@@@", "^:=", "...", ">>>",
+	":=", "->", ":>", "==", "!=", "<=", ">=", "&&", "||", "/.", "//", "/@", "@@", "++", "--",
+	"+=", "-=", "*=", "/=", "<>", "^=", "::", ";;", "..", "~~", "/;", "@*", "/*", "=.", ">>", "<<",
+	"**", "^^", "!!", "<-", "|->",
 }
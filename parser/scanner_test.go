@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func scanKinds(s string) (kinds []Kind, vals []string) {
+	for _, t := range NewScanner(strings.NewReader(s)).ScanAll() {
+		if t.Kind == Whitespace {
+			continue
+		}
+		kinds = append(kinds, t.Kind)
+		vals = append(vals, t.Val)
+	}
+	return
+}
+
+func TestScannerDefinition(t *testing.T) {
+	kinds, vals := scanKinds("f[x_Integer, y__] := Plot[Sin[x], {x, 0, 2.5`10}] (* plot *)")
+	assert.Equal(t, []string{"f", "[", "x_Integer", ",", "y__", "]", ":=", "Plot", "[", "Sin", "[", "x", "]", ",",
+		"{", "x", ",", "0", ",", "2.5`10", "}", "]", "(* plot *)"}, vals)
+	assert.Equal(t, []Kind{Symbol, Bracket, Pattern, Operator, Pattern, Bracket, Operator, Symbol, Bracket, Symbol,
+		Bracket, Symbol, Bracket, Operator, Bracket, Symbol, Operator, Number, Operator, Number, Bracket, Bracket, Comment}, kinds)
+}
+
+func TestScannerOperators(t *testing.T) {
+	kinds, vals := scanKinds(`a === b /. x -> 1 //. c@@d; #1 + ## & /@ list → "s\"t"`)
+	assert.Equal(t, []string{"a", "===", "b", "/.", "x", "->", "1", "//.", "c", "@@", "d", ";", "#1", "+", "##", "&",
+		"/@", "list", "→", `"s\"t"`}, vals)
+	assert.Equal(t, []Kind{Slot, Operator, Slot, Operator, Operator, Symbol, Operator, String}, kinds[12:])
+}
+
+func TestScannerPositions(t *testing.T) {
+	s := NewScanner(strings.NewReader("a\n  bβ c"))
+	assert.Equal(t, Position{Line: 1, Column: 1, Offset: 0}, s.Scan().Pos)
+	s.Scan()
+	tok := s.Scan()
+	assert.Equal(t, "bβ", tok.Val)
+	assert.Equal(t, Position{Line: 2, Column: 3, Offset: 4}, tok.Pos)
+	s.Scan()
+	assert.Equal(t, Position{Line: 2, Column: 6, Offset: 8}, s.Scan().Pos)
+	assert.Equal(t, EOF, s.Scan().Kind)
+}
+
+func TestScannerState(t *testing.T) {
+	s := NewStateScanner(`(* a (* nested *)`, State{})
+	assert.Equal(t, Comment, s.Scan().Kind)
+	assert.Equal(t, State{Comment: 1}, s.State())
+
+	s = NewStateScanner(`still *) x "open`, s.State())
+	tok := s.Scan()
+	assert.Equal(t, Comment, tok.Kind)
+	assert.Equal(t, "still *)", tok.Val)
+	kinds, _ := scanKinds(` x "open`)
+	assert.Equal(t, []Kind{Symbol, String}, kinds)
+	s.ScanAll()
+	assert.Equal(t, State{String: true}, s.State())
+
+	s = NewStateScanner(`string" + 1`, s.State())
+	assert.Equal(t, `string"`, s.Scan().Val)
+	assert.Equal(t, State{}, s.State())
+}
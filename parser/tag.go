@@ -36,10 +36,10 @@ func (n *Tag) String() string {
 }
 
 // Pos reports the position of the first component of n or zero if it's empty.
-func (n *Tag) Pos() token.Pos {
+func (n *Tag) Pos() Position {
 	if n == nil {
-		return 0
+		return Position{}
 	}
 
-	return n.Token.Pos()
+	return n.Token.Pos
 }
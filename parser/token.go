@@ -1,8 +1,46 @@
 package parser
 
+import "fmt"
+
+// Kind is the lexical class of a Token.
+type Kind int
+
+const (
+	EOF Kind = iota
+	Illegal
+	Whitespace
+	// Comment is a possibly nested (* comment *).
+	Comment
+	String
+	Number
+	Symbol
+	// Pattern is a blank like _, x_, x__ or x_Integer.
+	Pattern
+	// Slot is a # or ## in a pure function.
+	Slot
+	Operator
+	// Bracket is one of [ ] { } ( ) <| |>.
+	Bracket
+)
+
+var kindNames = []string{"EOF", "Illegal", "Whitespace", "Comment", "String", "Number", "Symbol", "Pattern", "Slot", "Operator", "Bracket"}
+
+// String implements fmt.Stringer
+func (k Kind) String() string {
+	if k >= 0 && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
 // Token represents a terminal AST node.
 type Token struct {
-	Rune rune
+	Kind Kind
 	Val  string
 	Pos  Position
 }
+
+// End returns the byte offset just after the token.
+func (t Token) End() int {
+	return t.Pos.Offset + len(t.Val)
+}
@@ -0,0 +1,129 @@
+// Package raster draws typeset shapes and graphics into an image without a GPU.
+package raster
+
+import (
+	"gioui.org/f32"
+	"golang.org/x/image/vector"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Canvas is a software replacement for the Gio ops that shapes use to draw themselves.
+// Like op.TransformOp, Push moves the origin for everything that is drawn until the matching Pop.
+type Canvas struct {
+	Image *image.RGBA
+	// Color is used to fill paths and text.
+	Color color.RGBA
+
+	offset  f32.Point
+	offsets []f32.Point
+}
+
+// NewCanvas returns a canvas with a white background that draws in black.
+func NewCanvas(size image.Point) *Canvas {
+	img := image.NewRGBA(image.Rectangle{Max: size})
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	return &Canvas{Image: img, Color: color.RGBA{A: 255}}
+}
+
+// Push moves the origin by off relative to the current origin.
+func (c *Canvas) Push(off f32.Point) {
+	c.offsets = append(c.offsets, c.offset)
+	c.offset = c.offset.Add(off)
+}
+
+// Pop restores the origin from before the last Push.
+func (c *Canvas) Pop() {
+	n := len(c.offsets) - 1
+	c.offset = c.offsets[n]
+	c.offsets = c.offsets[:n]
+}
+
+// FillRect fills r with the current color.
+func (c *Canvas) FillRect(r f32.Rectangle) {
+	c.FillPolygons([]f32.Point{r.Min, {X: r.Max.X, Y: r.Min.Y}, r.Max, {X: r.Min.X, Y: r.Max.Y}})
+}
+
+// FillPolygons fills the closed polygons with the current color, overlapping polygons
+// need the same winding and a polygon with the opposite winding cuts a hole.
+func (c *Canvas) FillPolygons(polygons ...[]f32.Point) {
+	z := c.rasterizer()
+	for _, ps := range polygons {
+		if len(ps) < 3 {
+			continue
+		}
+		z.MoveTo(c.x(ps[0].X), c.y(ps[0].Y))
+		for _, p := range ps[1:] {
+			z.LineTo(c.x(p.X), c.y(p.Y))
+		}
+		z.ClosePath()
+	}
+	c.draw(z)
+}
+
+// StrokeLine draws a polyline through points that is width pixels wide.
+func (c *Canvas) StrokeLine(points []f32.Point, width float32) {
+	hw := width / 2
+	var polygons [][]f32.Point
+	for i := 1; i < len(points); i++ {
+		p, q := points[i-1], points[i]
+		d := q.Sub(p)
+		l := float32(math.Hypot(float64(d.X), float64(d.Y)))
+		if l == 0 {
+			continue
+		}
+		n := f32.Point{X: -d.Y / l * hw, Y: d.X / l * hw}
+		polygons = append(polygons, []f32.Point{p.Add(n), q.Add(n), q.Sub(n), p.Sub(n)})
+		if i < len(points)-1 {
+			// Round join, disk has the same winding as the segments.
+			polygons = append(polygons, disk(q, hw, -1))
+		}
+	}
+	c.FillPolygons(polygons...)
+}
+
+// StrokePolygon draws the closed outline through points.
+func (c *Canvas) StrokePolygon(points []f32.Point, width float32) {
+	if len(points) == 0 {
+		return
+	}
+	closed := append(append([]f32.Point{points[len(points)-1]}, points...), points[0])
+	c.StrokeLine(closed, width)
+}
+
+// StrokeCircle draws a ring around center that is width pixels wide.
+func (c *Canvas) StrokeCircle(center f32.Point, radius, width float32) {
+	outer := disk(center, radius+width/2, -1)
+	inner := disk(center, float32(math.Max(float64(radius-width/2), 0)), 1)
+	c.FillPolygons(outer, inner)
+}
+
+func (c *Canvas) rasterizer() *vector.Rasterizer {
+	size := c.Image.Bounds().Size()
+	return vector.NewRasterizer(size.X, size.Y)
+}
+
+func (c *Canvas) draw(z *vector.Rasterizer) {
+	z.Draw(c.Image, c.Image.Bounds(), image.NewUniform(c.Color), image.Point{})
+}
+
+func (c *Canvas) x(x float32) float32 {
+	return x + c.offset.X
+}
+
+func (c *Canvas) y(y float32) float32 {
+	return y + c.offset.Y
+}
+
+// disk approximates a circle with a polygon, dir is 1 or -1 for the winding.
+func disk(center f32.Point, radius float32, dir float64) []f32.Point {
+	n := int(math.Max(16, math.Ceil(float64(radius)*2)))
+	ps := make([]f32.Point, n)
+	for i := range ps {
+		a := dir * 2 * math.Pi * float64(i) / float64(n)
+		ps[i] = f32.Point{X: center.X + radius*float32(math.Cos(a)), Y: center.Y + radius*float32(math.Sin(a))}
+	}
+	return ps
+}
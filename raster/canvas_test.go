@@ -0,0 +1,46 @@
+package raster
+
+import (
+	"gioui.org/f32"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"testing"
+)
+
+var (
+	white = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	black = color.RGBA{A: 255}
+)
+
+func TestFillRect(t *testing.T) {
+	c := NewCanvas(image.Point{X: 10, Y: 10})
+	c.Push(f32.Point{X: 2, Y: 2})
+	c.FillRect(f32.Rectangle{Max: f32.Point{X: 4, Y: 4}})
+	c.Pop()
+	assert.Equal(t, white, c.Image.RGBAAt(1, 1))
+	assert.Equal(t, black, c.Image.RGBAAt(3, 3))
+	assert.Equal(t, white, c.Image.RGBAAt(7, 7))
+}
+
+func TestStrokeCircle(t *testing.T) {
+	c := NewCanvas(image.Point{X: 40, Y: 40})
+	c.StrokeCircle(f32.Point{X: 20, Y: 20}, 15, 4)
+	assert.Equal(t, white, c.Image.RGBAAt(20, 20))
+	assert.Equal(t, black, c.Image.RGBAAt(35, 20))
+	assert.Equal(t, white, c.Image.RGBAAt(1, 1))
+}
+
+func TestText(t *testing.T) {
+	c := NewCanvas(image.Point{X: 40, Y: 40})
+	c.Text("", 30, "I", nil, f32.Point{X: 5, Y: 30})
+	ink := 0
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			if c.Image.RGBAAt(x, y) != white {
+				ink++
+			}
+		}
+	}
+	assert.True(t, ink > 0)
+}
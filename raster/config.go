@@ -0,0 +1,36 @@
+package raster
+
+import (
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/unit"
+	"image"
+	"math"
+	"time"
+)
+
+// Config converts units to pixels for a layout.Context that is not attached to a window.
+type Config struct {
+	Scale float32
+}
+
+func (c *Config) Now() time.Time {
+	return time.Now()
+}
+
+func (c *Config) Px(v unit.Value) int {
+	scale := c.Scale
+	if v.U == unit.UnitPx {
+		scale = 1
+	}
+	return int(math.Round(float64(scale * v.V)))
+}
+
+// NewContext returns a layout.Context for measuring shapes, size is the maximum size a shape can take.
+func NewContext(scale float32, size image.Point) *layout.Context {
+	gtx := &layout.Context{Ops: new(op.Ops)}
+	gtx.Reset(&Config{Scale: scale}, size)
+	gtx.Constraints.Width.Min = 0
+	gtx.Constraints.Height.Min = 0
+	return gtx
+}
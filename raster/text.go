@@ -0,0 +1,86 @@
+package raster
+
+import (
+	"gioui.org/f32"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"sync"
+)
+
+// The Go fonts are the ones registered with gofont.Register, so text is drawn
+// with the same glyphs and advances that the Gio shaper measured.
+var fonts struct {
+	once          sync.Once
+	regular, mono *sfnt.Font
+}
+
+func loadFonts() {
+	fonts.regular, _ = sfnt.Parse(goregular.TTF)
+	fonts.mono, _ = sfnt.Parse(gomono.TTF)
+}
+
+func font(variant string) *sfnt.Font {
+	fonts.once.Do(loadFonts)
+	if variant == "Mono" {
+		return fonts.mono
+	}
+	return fonts.regular
+}
+
+// Text draws str with its baseline starting at dot, size is the font size in pixels.
+// The advances come from the text layout, when they are missing the advances of the font are used.
+func (c *Canvas) Text(variant string, size float32, str string, advances []fixed.Int26_6, dot f32.Point) {
+	f := font(variant)
+	if f == nil {
+		return
+	}
+	var buf sfnt.Buffer
+	ppem := fixed.Int26_6(size * 64)
+	z := c.rasterizer()
+	x := dot.X
+	i := 0
+	for _, r := range str {
+		idx, err := f.GlyphIndex(&buf, r)
+		if err != nil {
+			continue
+		}
+		segments, err := f.LoadGlyph(&buf, idx, ppem, nil)
+		if err == nil {
+			for _, s := range segments {
+				p := func(j int) (float32, float32) {
+					return c.x(x + fix(s.Args[j].X)), c.y(dot.Y + fix(s.Args[j].Y))
+				}
+				switch s.Op {
+				case sfnt.SegmentOpMoveTo:
+					z.ClosePath()
+					z.MoveTo(p(0))
+				case sfnt.SegmentOpLineTo:
+					z.LineTo(p(0))
+				case sfnt.SegmentOpQuadTo:
+					bx, by := p(0)
+					cx, cy := p(1)
+					z.QuadTo(bx, by, cx, cy)
+				case sfnt.SegmentOpCubeTo:
+					bx, by := p(0)
+					cx, cy := p(1)
+					dx, dy := p(2)
+					z.CubeTo(bx, by, cx, cy, dx, dy)
+				}
+			}
+			z.ClosePath()
+		}
+		if i < len(advances) {
+			x += fix(advances[i])
+		} else if adv, err := f.GlyphAdvance(&buf, idx, ppem, 0); err == nil {
+			x += fix(adv)
+		}
+		i++
+	}
+	c.draw(z)
+}
+
+func fix(v fixed.Int26_6) float32 {
+	return float32(v) / 64
+}
@@ -0,0 +1,280 @@
+// Package search is a full-text index of the documentation of the builtin symbols.
+package search
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/analysis/lang/en"
+	"github.com/blevesearch/bleve/mapping"
+	"github.com/corywalker/expreduce/expreduce"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// version changes when the mapping or the documents change shape, an index on disk with another
+// version is deleted and created again.
+const version = 2
+
+var (
+	fingerprintKey = []byte("fingerprint")
+	namesKey       = []byte("names")
+	versionKey     = []byte("version")
+)
+
+// Doc is the documentation of a symbol.
+type Doc struct {
+	Name       string
+	Usage      string
+	Category   string
+	Attributes []string
+	// Examples show how the symbol is used.
+	Examples []Example
+}
+
+// Example is an input and the output it evaluates to, the output is empty when it is not known.
+type Example struct {
+	In  string
+	Out string
+}
+
+// Result is a symbol that matches a query.
+type Result struct {
+	Name  string
+	Usage string
+	Score float64
+}
+
+// Builtins returns the documentation of expreduce's builtin symbols.
+func Builtins() []Doc {
+	es := expreduce.NewEvalState()
+	var docs []Doc
+	for _, set := range expreduce.GetAllDefinitions() {
+		for _, def := range set.Defs {
+			if def.OmitDocumentation {
+				continue
+			}
+			def.AnnotateWithDynamic(es)
+			docs = append(docs, Doc{
+				Name:       def.Name,
+				Usage:      def.Usage,
+				Category:   set.Name,
+				Attributes: def.Attributes,
+				Examples:   examples(append(def.SimpleExamples, def.FurtherExamples...)),
+			})
+		}
+	}
+	return docs
+}
+
+func examples(tests []expreduce.TestInstruction) []Example {
+	var ex []Example
+	for _, t := range tests {
+		switch t := t.(type) {
+		case *expreduce.SameTest:
+			ex = append(ex, Example{In: t.In, Out: t.Out})
+		case *expreduce.StringTest:
+			ex = append(ex, Example{In: t.In, Out: t.Out})
+		case *expreduce.ExampleOnlyInstruction:
+			ex = append(ex, Example{In: t.In, Out: t.Out})
+		}
+	}
+	return ex
+}
+
+var builtins struct {
+	once sync.Once
+	docs map[string]Doc
+}
+
+// Lookup returns the documentation of the builtin with the name.
+func Lookup(name string) (Doc, bool) {
+	builtins.once.Do(func() {
+		builtins.docs = map[string]Doc{}
+		for _, d := range Builtins() {
+			builtins.docs[d.Name] = d
+		}
+	})
+	d, ok := builtins.docs[name]
+	return d, ok
+}
+
+// Index is a Bleve index of documentation.
+type Index struct {
+	index bleve.Index
+}
+
+// DefaultPath is the location of the index in the user's cache directory.
+func DefaultPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "foxtrot", "docs.bleve"), nil
+}
+
+// Open opens the index at path and creates it when it does not exist,
+// an index of another version is deleted and created again.
+func Open(path string) (*Index, error) {
+	index, err := bleve.Open(path)
+	if err == nil {
+		v, err := index.GetInternal(versionKey)
+		if err != nil {
+			index.Close()
+			return nil, err
+		}
+		if string(v) == strconv.Itoa(version) {
+			return &Index{index: index}, nil
+		}
+		if err := index.Close(); err != nil {
+			return nil, err
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return nil, err
+		}
+	} else if err != bleve.ErrorIndexPathDoesNotExist {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return newIndex(bleve.New(path, newMapping()))
+}
+
+// NewMemIndex returns an index that is kept in memory.
+func NewMemIndex() (*Index, error) {
+	return newIndex(bleve.NewMemOnly(newMapping()))
+}
+
+// newIndex stores the version in a new index.
+func newIndex(index bleve.Index, err error) (*Index, error) {
+	if err != nil {
+		return nil, err
+	}
+	if err := index.SetInternal(versionKey, []byte(strconv.Itoa(version))); err != nil {
+		index.Close()
+		return nil, err
+	}
+	return &Index{index: index}, nil
+}
+
+func newMapping() mapping.IndexMapping {
+	text := bleve.NewTextFieldMapping()
+	text.Analyzer = en.AnalyzerName
+	name := bleve.NewTextFieldMapping()
+	name.Analyzer = standard.Name
+	attribute := bleve.NewTextFieldMapping()
+	attribute.Analyzer = keyword.Name
+	doc := bleve.NewDocumentMapping()
+	doc.AddFieldMappingsAt("Name", name)
+	doc.AddFieldMappingsAt("Usage", text)
+	doc.AddFieldMappingsAt("Category", text)
+	doc.AddFieldMappingsAt("Attributes", attribute)
+	example := bleve.NewDocumentMapping()
+	example.AddFieldMappingsAt("In", text)
+	example.AddFieldMappingsAt("Out", text)
+	doc.AddSubDocumentMapping("Examples", example)
+	m := bleve.NewIndexMapping()
+	m.DefaultMapping = doc
+	m.DefaultAnalyzer = en.AnalyzerName
+	return m
+}
+
+// Close closes the index.
+func (ix *Index) Close() error {
+	return ix.index.Close()
+}
+
+func fingerprint(docs []Doc) []byte {
+	h := sha256.New()
+	json.NewEncoder(h).Encode(struct {
+		Version int
+		Docs    []Doc
+	}{version, docs})
+	return h.Sum(nil)
+}
+
+// Sync indexes docs when they are different from the documents that were indexed before,
+// documents of symbols that no longer exist are removed. It reports whether the index changed.
+func (ix *Index) Sync(docs []Doc) (bool, error) {
+	docs = append([]Doc{}, docs...)
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Name < docs[j].Name })
+	fp := fingerprint(docs)
+	old, err := ix.index.GetInternal(fingerprintKey)
+	if err != nil {
+		return false, err
+	}
+	if string(old) == string(fp) {
+		return false, nil
+	}
+	var oldNames []string
+	if b, err := ix.index.GetInternal(namesKey); err == nil && b != nil {
+		json.Unmarshal(b, &oldNames)
+	}
+	names := map[string]bool{}
+	batch := ix.index.NewBatch()
+	for _, d := range docs {
+		names[d.Name] = true
+		if err := batch.Index(d.Name, d); err != nil {
+			return false, err
+		}
+	}
+	for _, n := range oldNames {
+		if !names[n] {
+			batch.Delete(n)
+		}
+	}
+	if err := ix.index.Batch(batch); err != nil {
+		return false, err
+	}
+	var list []string
+	for _, d := range docs {
+		list = append(list, d.Name)
+	}
+	b, _ := json.Marshal(list)
+	if err := ix.index.SetInternal(namesKey, b); err != nil {
+		return true, err
+	}
+	return true, ix.index.SetInternal(fingerprintKey, fp)
+}
+
+// Search returns up to n symbols that match the query q, the names of symbols weigh more than their usage
+// and examples. The query is natural text like "how do I solve an equation".
+func (ix *Index) Search(q string, n int) ([]Result, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return nil, nil
+	}
+	name := bleve.NewMatchQuery(q)
+	name.SetField("Name")
+	name.SetBoost(5)
+	text := bleve.NewMatchQuery(q)
+	query := bleve.NewDisjunctionQuery(name, text)
+	if !strings.ContainsAny(q, " \t") {
+		prefix := bleve.NewPrefixQuery(strings.ToLower(q))
+		prefix.SetField("Name")
+		prefix.SetBoost(2)
+		query.AddQuery(prefix)
+	}
+	req := bleve.NewSearchRequestOptions(query, n, 0, false)
+	req.Fields = []string{"Usage"}
+	res, err := ix.index.Search(req)
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	for _, hit := range res.Hits {
+		r := Result{Name: hit.ID, Score: hit.Score}
+		if u, ok := hit.Fields["Usage"].(string); ok {
+			r.Usage = u
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
@@ -0,0 +1,97 @@
+package search
+
+import (
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/analysis/lang/en"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testDocs = []Doc{
+	{Name: "Solve", Usage: "`Solve[eqn, var]` solves `eqn` for `var`.", Examples: []Example{{In: "Solve[x^2 == 4, x]", Out: "{{x -> -2}, {x -> 2}}"}}},
+	{Name: "Sin", Usage: "`Sin[x]` is the sine of `x`.", Attributes: []string{"Listable"}},
+	{Name: "SinIntegral", Usage: "`SinIntegral[x]` is the sine integral of `x`."},
+	{Name: "Plot", Usage: "`Plot[fn, {var, min, max}]` plots `fn` over the range."},
+}
+
+func names(results []Result) []string {
+	var ns []string
+	for _, r := range results {
+		ns = append(ns, r.Name)
+	}
+	return ns
+}
+
+func TestSearch(t *testing.T) {
+	ix, err := NewMemIndex()
+	assert.NoError(t, err)
+	defer ix.Close()
+	changed, err := ix.Sync(testDocs)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	results, err := ix.Search("how do I solve an equation", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "Solve", results[0].Name)
+	assert.Equal(t, testDocs[0].Usage, results[0].Usage)
+
+	results, err = ix.Search("Sin", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Sin", "SinIntegral"}, names(results))
+
+	results, err = ix.Search("  ", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestSync(t *testing.T) {
+	dir, err := ioutil.TempDir("", "search")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "docs.bleve")
+
+	ix, err := Open(path)
+	assert.NoError(t, err)
+	changed, err := ix.Sync(testDocs)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.NoError(t, ix.Close())
+
+	ix, err = Open(path)
+	assert.NoError(t, err)
+	defer ix.Close()
+	changed, err = ix.Sync(testDocs)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	changed, err = ix.Sync(testDocs[1:])
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	results, err := ix.Search("solve", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestOpenOtherVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "search")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "docs.bleve")
+
+	old, err := bleve.New(path, bleve.NewIndexMapping())
+	assert.NoError(t, err)
+	assert.NoError(t, old.SetInternal(fingerprintKey, fingerprint(testDocs)))
+	assert.NoError(t, old.SetInternal(versionKey, []byte("1")))
+	assert.NoError(t, old.Close())
+
+	ix, err := Open(path)
+	assert.NoError(t, err)
+	defer ix.Close()
+	assert.Equal(t, en.AnalyzerName, ix.index.Mapping().AnalyzerNameForPath("Usage"))
+	changed, err := ix.Sync(testDocs)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+}
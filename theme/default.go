@@ -7,46 +7,67 @@ import (
 	"gioui.org/widget/material"
 	"github.com/wrnrlr/foxtrot/editor"
 	"github.com/wrnrlr/foxtrot/util"
+	"time"
 )
 
 func DefaultStyles() *Styles {
 	styles := Styles{}
 	shaper := font.Default()
+	styles.Syntax = &editor.SyntaxStyle{
+		Builtin:  util.Rgb(0x1e5bb5),
+		String:   util.Rgb(0x7cb342),
+		Number:   util.Rgb(0x8e44ad),
+		Comment:  util.Rgb(0x9e9e9e),
+		Pattern:  util.Rgb(0x2e7d32),
+		Operator: util.Rgb(0x616161)}
 	styles.Foxtrot = editor.EditorStyle{
-		Font:       text.Font{Variant: "Mono", Size: unit.Sp(18)},
-		Color:      util.Black,
-		CaretColor: util.Black,
-		Shaper:     shaper}
+		Font:            text.Font{Variant: "Mono", Size: unit.Sp(18)},
+		Color:           util.Black,
+		HintColor:       util.LightGrey,
+		CaretColor:      util.Black,
+		SelectionColor:  util.SelectedColor,
+		BracketColor:    util.Rgb(0xdcedc8),
+		Syntax:          styles.Syntax,
+		CompletionDelay: 500 * time.Millisecond,
+		Shaper:          shaper}
 	styles.H1 = editor.EditorStyle{
-		Font:       text.Font{Size: unit.Sp(38)},
-		Color:      util.Black,
-		CaretColor: util.Black,
-		Shaper:     shaper}
+		Font:           text.Font{Size: unit.Sp(38)},
+		Color:          util.Black,
+		CaretColor:     util.Black,
+		SelectionColor: util.SelectedColor,
+		Shaper:         shaper}
 	styles.H2 = editor.EditorStyle{
-		Font:       text.Font{Size: unit.Sp(32)},
-		Color:      util.Black,
-		CaretColor: util.Black,
-		Shaper:     shaper}
+		Font:           text.Font{Size: unit.Sp(32)},
+		Color:          util.Black,
+		CaretColor:     util.Black,
+		SelectionColor: util.SelectedColor,
+		Shaper:         shaper}
 	styles.H3 = editor.EditorStyle{
-		Font:       text.Font{Size: unit.Sp(26)},
-		Color:      util.Black,
-		CaretColor: util.Black,
-		Shaper:     shaper}
+		Font:           text.Font{Size: unit.Sp(26)},
+		Color:          util.Black,
+		CaretColor:     util.Black,
+		SelectionColor: util.SelectedColor,
+		Shaper:         shaper}
 	styles.H4 = editor.EditorStyle{
-		Font:       text.Font{Size: unit.Sp(20)},
-		Color:      util.Black,
-		CaretColor: util.Black,
-		Shaper:     shaper}
+		Font:           text.Font{Size: unit.Sp(20)},
+		Color:          util.Black,
+		CaretColor:     util.Black,
+		SelectionColor: util.SelectedColor,
+		Shaper:         shaper}
 	styles.Text = editor.EditorStyle{
-		Font:       text.Font{Size: unit.Sp(16)},
-		Color:      util.Black,
-		CaretColor: util.Black,
-		Shaper:     shaper}
+		Font:           text.Font{Size: unit.Sp(16)},
+		Color:          util.Black,
+		CaretColor:     util.Black,
+		SelectionColor: util.SelectedColor,
+		Shaper:         shaper}
 	styles.Code = editor.EditorStyle{
-		Font:       text.Font{Variant: "Mono", Size: unit.Sp(16)},
-		Color:      util.Black,
-		CaretColor: util.Black,
-		Shaper:     shaper}
+		Font:           text.Font{Variant: "Mono", Size: unit.Sp(16)},
+		Color:          util.Black,
+		CaretColor:     util.Black,
+		SelectionColor: util.SelectedColor,
+		BracketColor:   util.Rgb(0xdcedc8),
+		Syntax:         styles.Syntax,
+		Shaper:         shaper}
 	styles.Theme = material.NewTheme()
 	styles.Theme.Shaper = shaper
 	styles.Theme.Color.Text = util.LightGrey
@@ -8,6 +8,8 @@ import (
 
 type Styles struct {
 	Foxtrot, H1, H2, H3, H4, H5, H6, Text, Code editor.EditorStyle
-	Theme                                       *material.Theme
-	shaper                                      *text.Shaper
+	// Syntax are the colors of the tokens in the input and code cells.
+	Syntax *editor.SyntaxStyle
+	Theme  *material.Theme
+	shaper *text.Shaper
 }
@@ -7,6 +7,7 @@ import (
 	"gioui.org/op/clip"
 	"gioui.org/op/paint"
 	"gioui.org/unit"
+	"github.com/wrnrlr/foxtrot/raster"
 	"github.com/wrnrlr/foxtrot/style"
 	"image"
 )
@@ -69,6 +70,26 @@ func (f *Fraction) Layout(gtx *layout.Context, s style.Style) {
 	gtx.Dimensions = dims
 }
 
+func (f *Fraction) Rasterize(gtx *layout.Context, s style.Style, c *raster.Canvas) {
+	dims := f.Dimensions(gtx, s)
+	dN := f.Numerator.Dimensions(gtx, s)
+	c.Push(f32.Point{X: float32(dims.Size.X-dN.Size.X) / 2, Y: 0})
+	f.Numerator.Rasterize(gtx, s, c)
+	c.Pop()
+
+	topOffset := float32(dN.Size.Y + gtx.Px(unit.Sp(1)))
+	size := float32(gtx.Px(unit.Sp(1)))
+	c.FillRect(f32.Rectangle{Min: f32.Point{Y: topOffset}, Max: f32.Point{X: float32(dims.Size.X), Y: topOffset + size}})
+
+	topOffset += float32(gtx.Px(unit.Sp(1)))
+	dD := f.Denominator.Dimensions(gtx, s)
+	c.Push(f32.Point{X: float32(dims.Size.X-dD.Size.X) / 2, Y: topOffset})
+	f.Denominator.Rasterize(gtx, s, c)
+	c.Pop()
+
+	gtx.Dimensions = dims
+}
+
 func max(x, y int) int {
 	if x > y {
 		return x
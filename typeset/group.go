@@ -4,6 +4,7 @@ import (
 	"gioui.org/f32"
 	"gioui.org/layout"
 	"gioui.org/op"
+	"github.com/wrnrlr/foxtrot/raster"
 	"github.com/wrnrlr/foxtrot/style"
 )
 
@@ -43,6 +44,23 @@ func (g *Group) Layout(gtx *layout.Context, s style.Style) {
 	gtx.Dimensions = g.Dimensions(gtx, s)
 }
 
+func (g *Group) Rasterize(gtx *layout.Context, s style.Style, c *raster.Canvas) {
+	lineOffset := 0
+	for _, l := range g.lines(gtx, s) {
+		ld := l.dimensions(gtx, s)
+		x := 0
+		for _, p := range l.shapes {
+			d := p.Dimensions(gtx, s)
+			c.Push(f32.Point{X: float32(x), Y: float32((ld.Size.Y-d.Size.Y)/2 + lineOffset)})
+			p.Rasterize(gtx, s, c)
+			c.Pop()
+			x += d.Size.X
+		}
+		lineOffset += ld.Size.Y
+	}
+	gtx.Dimensions = g.Dimensions(gtx, s)
+}
+
 func (g *Group) lines(gtx *layout.Context, s style.Style) []line {
 	maxWidth := gtx.Constraints.Width.Max
 	lineWidth := 0
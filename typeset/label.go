@@ -2,6 +2,7 @@ package typeset
 
 import (
 	"fmt"
+	"github.com/wrnrlr/foxtrot/raster"
 	"github.com/wrnrlr/foxtrot/style"
 	"image"
 
@@ -61,6 +62,29 @@ func (l Label) Layout(gtx *layout.Context, s style.Style) {
 	gtx.Dimensions = dims
 }
 
+func (l Label) Rasterize(gtx *layout.Context, s style.Style, c *raster.Canvas) {
+	options := text.LayoutOptions{MaxWidth: l.MaxWidth}
+	textLayout := s.Shaper.Layout(gtx, s.Font, l.Text, options)
+	dims := linesDimens(textLayout.Lines)
+	clip := textPadding(textLayout.Lines)
+	clip.Max = clip.Max.Add(dims.Size)
+	it := LineIterator{
+		Lines:     textLayout.Lines,
+		Clip:      clip,
+		Alignment: l.Alignment,
+		Width:     dims.Size.X,
+	}
+	size := float32(gtx.Px(s.Font.Size))
+	for {
+		str, off, ok := it.Next()
+		if !ok {
+			break
+		}
+		c.Text(string(s.Font.Variant), size, str.String, str.Advances, off)
+	}
+	gtx.Dimensions = dims
+}
+
 func (l *Label) Dimensions(gtx *layout.Context, s style.Style) layout.Dimensions {
 	options := text.LayoutOptions{MaxWidth: l.MaxWidth}
 	textLayout := s.Shaper.Layout(gtx, s.Font, l.Text, options)
@@ -4,6 +4,7 @@ import (
 	"gioui.org/f32"
 	"gioui.org/layout"
 	"gioui.org/op"
+	"github.com/wrnrlr/foxtrot/raster"
 	"github.com/wrnrlr/foxtrot/style"
 )
 
@@ -77,3 +78,26 @@ func (o *Operator) Layout(gtx *layout.Context, s style.Style) {
 		stack.Pop()
 	}
 }
+
+func (o *Operator) Rasterize(gtx *layout.Context, s style.Style, c *raster.Canvas) {
+	dims := o.Dimensions(gtx, s)
+	offset := f32.Point{X: 0, Y: 0}
+	o.Left.Rasterize(gtx, s, c)
+	offset.X += float32(o.Left.Dimensions(gtx, s).Size.X)
+
+	od := o.Symbol.Dimensions(gtx, s)
+	offset.Y = float32((dims.Size.Y - od.Size.Y) / 2)
+	c.Push(offset)
+	o.Symbol.Rasterize(gtx, s, c)
+	c.Pop()
+	offset.X += float32(od.Size.X)
+
+	if o.Right != nil {
+		rd := o.Right.Dimensions(gtx, s)
+		offset.Y = float32((dims.Size.Y - rd.Size.Y) / 2)
+		c.Push(offset)
+		o.Right.Rasterize(gtx, s, c)
+		c.Pop()
+	}
+	gtx.Dimensions = dims
+}
@@ -3,12 +3,15 @@ package typeset
 import (
 	"gioui.org/layout"
 	"gioui.org/text"
+	"github.com/wrnrlr/foxtrot/raster"
 	"github.com/wrnrlr/foxtrot/style"
 )
 
 type Shape interface {
 	Dimensions(gtx *layout.Context, s style.Style) layout.Dimensions
 	Layout(gtx *layout.Context, s style.Style)
+	// Rasterize draws the shape on a software canvas at the same place as Layout.
+	Rasterize(gtx *layout.Context, s style.Style, c *raster.Canvas)
 }
 
 func scaleDownFont(font text.Font) text.Font {
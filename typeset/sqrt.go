@@ -7,6 +7,7 @@ import (
 	"gioui.org/op/clip"
 	"gioui.org/op/paint"
 	"gioui.org/unit"
+	"github.com/wrnrlr/foxtrot/raster"
 	"github.com/wrnrlr/foxtrot/style"
 )
 
@@ -63,3 +64,16 @@ func (o *sqrt) Layout(gtx *layout.Context, s style.Style) {
 	}.Add(gtx.Ops)
 	stack.Pop()
 }
+
+func (o *sqrt) Rasterize(gtx *layout.Context, s style.Style, c *raster.Canvas) {
+	SqrtSymbol.Rasterize(gtx, s, c)
+	signWidth := float32(SqrtSymbol.Dimensions(gtx, s).Size.X)
+
+	c.Push(f32.Point{X: signWidth, Y: 0})
+	o.Content.Rasterize(gtx, s, c)
+	contentWidth := float32(o.Content.Dimensions(gtx, s).Size.X)
+	width := float32(gtx.Px(unit.Sp(1)))
+	c.FillRect(f32.Rectangle{Max: f32.Point{X: contentWidth, Y: width}})
+	c.Pop()
+	gtx.Dimensions = o.Dimensions(gtx, s)
+}
@@ -4,6 +4,7 @@ import (
 	"gioui.org/f32"
 	"gioui.org/layout"
 	"gioui.org/op"
+	"github.com/wrnrlr/foxtrot/raster"
 	"github.com/wrnrlr/foxtrot/style"
 )
 
@@ -67,3 +68,31 @@ func (w *Word) Layout(gtx *layout.Context, s style.Style) {
 	}
 	gtx.Dimensions = w.Dimensions(gtx, s)
 }
+
+func (w *Word) Rasterize(gtx *layout.Context, s style.Style, c *raster.Canvas) {
+	offset := f32.Point{X: 0, Y: 0}
+	metrics := s.Shaper.Metrics(gtx, s.Font)
+	xHeight := metrics.XHeight.Ceil()
+	descent := metrics.Descent.Ceil()
+	smallerFont := s
+	smallerFont.Font = scaleDownFont(s.Font)
+	contentDimesions := w.Content.Dimensions(gtx, s)
+	if w.Superscript != nil {
+		superscriptDims := w.Superscript.Dimensions(gtx, smallerFont)
+		c.Push(f32.Point{X: float32(contentDimesions.Size.X), Y: 0})
+		w.Superscript.Rasterize(gtx, smallerFont, c)
+		offset.Y = float32(superscriptDims.Size.Y - xHeight)
+		c.Pop()
+	}
+	c.Push(offset)
+	w.Content.Rasterize(gtx, s, c)
+	offset.X = float32(contentDimesions.Size.X)
+	offset.Y += float32(contentDimesions.Size.Y - descent)
+	c.Pop()
+	if w.Subscript != nil {
+		c.Push(offset)
+		w.Subscript.Rasterize(gtx, smallerFont, c)
+		c.Pop()
+	}
+	gtx.Dimensions = w.Dimensions(gtx, s)
+}
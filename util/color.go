@@ -14,6 +14,7 @@ var (
 
 	White         = Rgb(0xffffff)
 	Black         = Rgb(0x000000)
+	Grey          = Rgb(0x757575)
 	Red           = Rgb(0xe53935)
 	Blue          = Rgb(0x1e88e5)
 	SelectedColor = Rgb(0xe1f5fe)